@@ -0,0 +1,472 @@
+package downloader // Single-file PDF download with resume, verification and retry
+
+import (
+	"bufio"         // For peeking at the start of the response body
+	"bytes"         // For comparing byte signatures
+	"context"       // For managing deadlines, cancellation signals, etc.
+	"crypto/sha256" // For hashing downloaded content
+	"crypto/tls"    // For recognizing certificate failures
+	"encoding/hex"  // For encoding hashes as text
+	"errors"        // For inspecting wrapped errors
+	"fmt"           // For formatted I/O
+	"io"            // For I/O primitives (Read, Write, etc.)
+	"math/rand/v2"  // For randomized retry jitter
+	"net"           // For detecting network errors
+	"net/http"      // For HTTP client functionality
+	"net/url"       // For parsing and building URLs
+	"os"            // For file and system operations
+	"path/filepath" // For creating template subdirectories
+	"strings"       // For string manipulation
+	"time"          // For working with time durations and timestamps
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link store, manifest, and file helpers
+)
+
+// Errors DownloadPDF wraps to say why a download failed; match them with errors.Is. None of them is
+// retried: the same request would get the same answer. Retried are only *ErrBadStatus with a 5xx or
+// 429 Code, network errors (net.Error, including attempt timeouts, but not a *tls.CertificateVerificationError)
+// and bodies cut off mid-transfer, and only while the download's context is still live.
+var (
+	ErrWrongContentType = errors.New("wrong content type")   // The Content-Type header isn't one of ContentTypes
+	ErrNotPDF           = errors.New("not a PDF")            // The body doesn't start with its file type's signature, e.g. %PDF-
+	ErrZeroBytes        = errors.New("empty response")       // The body had no bytes at all
+	ErrSoft404          = errors.New("HTML error page")      // The server answered 200 with an HTML page, usually "Not Found"
+	ErrTooLarge         = errors.New("over the size limit")  // The PDF is bigger than MaxSize
+	ErrTooSmall         = errors.New("under the size limit") // The PDF is smaller than MinSize, likely a placeholder
+	ErrMismatch         = errors.New("checksum mismatch")    // The content doesn't match its expected SHA-256
+)
+
+// DownloadPDF downloads a PDF file from the given URL and saves it to filePath.
+// An existing file is refreshed with a conditional GET when previous holds its ETag or Last-Modified.
+func (downloader *Downloader) DownloadPDF(ctx context.Context, finalURL, filePath string, previous *storage.DownloadRecord) (*storage.DownloadRecord, error) {
+	startTime := time.Now()                                                                     // Start of the download, for the duration log field
+	revalidate := !downloader.Force && storage.FileExists(filePath) && previous.CanRevalidate() // Ask the server whether our copy is stale
+	if !downloader.Force && storage.FileExists(filePath) && !revalidate {                       // Skip download if file already exists
+		if !downloader.OverwriteIfLarger || !downloader.remoteIsLarger(ctx, finalURL, filePath) { // Keep our copy unless the server has a bigger one
+			downloader.logger().Log(ctx, storage.LevelSkip, "file already exists, skipping", "url", finalURL, "file", filePath) // Log skip message
+			return nil, nil
+		}
+	}
+
+	tempPath := filePath + ".part"                                        // Temporary file that is renamed into place once complete
+	signature := storage.FileSignature(filePath)                          // Leading bytes the file must have, if its type has known ones
+	resumeOffset := downloader.partialDownloadOffset(tempPath, signature) // Bytes already fetched by an interrupted download
+	var conditional *storage.DownloadRecord                               // Validators to send, if revalidating
+	if revalidate {                                                       // A refresh always fetches the whole new file
+		resumeOffset = 0
+		conditional = previous
+	}
+
+	attemptCtx, cancelAttempt := downloader.attemptContext(ctx) // Bound this attempt without cancelling the whole run
+	defer cancelAttempt()
+
+	resp, err := downloader.requestPDF(attemptCtx, finalURL, resumeOffset, conditional) // Send GET request to download PDF
+	if err != nil {                                                                     // Handle GET error
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified { // Our copy is current
+		resp.Body.Close()
+		downloader.logger().Log(ctx, storage.LevelSkip, "not modified, skipping", "url", finalURL, "file", filePath, "duration", time.Since(startTime))
+		return nil, nil
+	}
+	if resumeOffset > 0 && resp.StatusCode == http.StatusOK { // Server ignored the Range header
+		downloader.logger().Info("server ignored range request, restarting download", "url", finalURL)
+		resumeOffset = 0 // The full body is being sent; start over
+	} else if resumeOffset > 0 && (!resumeAccepted(resp, resumeOffset) || contentEncoding(resp) != "") { // Server can't continue from our offset; encoded ranges can't be decoded mid-stream
+		downloader.logger().Warn("cannot resume download, restarting", "url", finalURL, "offset", resumeOffset, "status", resp.StatusCode)
+		resp.Body.Close()                                               // Discard the unusable response
+		os.Remove(tempPath)                                             // Discard the partial file
+		resumeOffset = 0                                                // Start over from the first byte
+		resp, err = downloader.requestPDF(attemptCtx, finalURL, 0, nil) // Request the whole file
+		if err != nil {                                                 // Handle GET error
+			return nil, err
+		}
+	}
+	defer resp.Body.Close() // Ensure response body is closed
+
+	expectedStatus := http.StatusOK // A fresh download must return 200
+	if resumeOffset > 0 {           // A resumed download must return 206
+		expectedStatus = http.StatusPartialContent
+	}
+	if resp.StatusCode != expectedStatus { // Check for the expected status
+		return nil, &ErrBadStatus{URL: finalURL, Status: resp.Status, Code: resp.StatusCode} // Report HTTP error
+	}
+
+	contentType := resp.Header.Get("Content-Type")  // Get content type header
+	if strings.Contains(contentType, "text/html") { // A page, not a file: almost always an error page
+		return nil, fmt.Errorf("%w: %s has content type %q", ErrSoft404, finalURL, contentType)
+	}
+	if !storage.ContentTypeAllowed(contentType, downloader.contentTypes()) { // Ensure content is an accepted document type
+		return nil, fmt.Errorf("%w: %s has content type %q", ErrWrongContentType, finalURL, contentType)
+	}
+
+	if downloader.MaxSize > 0 && resp.ContentLength > 0 && resumeOffset+resp.ContentLength > downloader.MaxSize { // Reject oversized files before reading them
+		return nil, fmt.Errorf("%w: %s is %d bytes, limit %d", ErrTooLarge, finalURL, resumeOffset+resp.ContentLength, downloader.MaxSize)
+	}
+
+	var received io.Reader = resp.Body // Body as it arrives off the wire
+	if downloader.StallTimeout > 0 {   // Abandon the attempt if data stops flowing, long before DownloadTimeout
+		stall := newStallReader(resp.Body, downloader.StallTimeout, cancelAttempt)
+		defer stall.stop()
+		received = stall
+	}
+	decoded, err := decodeBody(received, contentEncoding(resp)) // Undo any gzip or deflate encoding the transport left in place
+	if err != nil {                                             // Corrupt or unsupported encoding
+		return nil, fmt.Errorf("failed to decode PDF data from %s: %w", finalURL, err)
+	}
+	if downloader.MaxSize > 0 { // Stop reading one byte past the limit so an overrun can be detected
+		decoded = io.LimitReader(decoded, downloader.MaxSize-resumeOffset+1)
+	}
+	body := bufio.NewReader(decoded) // Buffered reader so the signature can be inspected
+	if resumeOffset == 0 {           // A resumed body starts mid-file; the .part file was checked instead
+		prefix, err := body.Peek(htmlSniffLength)  // Look at the first bytes without consuming them
+		if err != nil && !errors.Is(err, io.EOF) { // Handle read error (may be transient)
+			return nil, fmt.Errorf("failed to read PDF data from %s: %w", finalURL, err)
+		}
+		if len(prefix) == 0 { // Empty body
+			return nil, fmt.Errorf("%w: downloaded 0 bytes for %s; not creating file", ErrZeroBytes, finalURL)
+		}
+		head := prefix[:min(len(prefix), 8)] // Enough to show in errors
+		if looksLikeHTML(prefix) {           // Mislabelled error page
+			return nil, fmt.Errorf("%w: content from %s starts with %q", ErrSoft404, finalURL, head)
+		}
+		if !bytes.HasPrefix(prefix, signature) { // The magic number is authoritative, whatever the header says
+			return nil, fmt.Errorf("%w: content from %s starts with %q", ErrNotPDF, finalURL, head)
+		}
+	}
+
+	hasher := sha256.New()                                            // Hash the content while it streams to disk
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil { // Name templates may place files in subdirectories
+		return nil, fmt.Errorf("failed to create directory for %s: %w", finalURL, err)
+	}
+	out, err := openPartialFile(tempPath, resumeOffset, hasher) // Create or reopen the temporary output file
+	if err != nil {                                             // Handle file creation error
+		return nil, fmt.Errorf("failed to create file for %s: %w", finalURL, err)
+	}
+
+	written, err := io.Copy(io.MultiWriter(out, hasher), body) // Stream response body into the temporary file
+	closeErr := out.Close()                                    // Close the temporary file before renaming or removing it
+	if err != nil {                                            // Handle copy error (including cancellation)
+		if ctx.Err() != nil { // A cancelled run discards its partial file; an expired attempt keeps it to resume
+			os.Remove(tempPath)
+		} // Otherwise keep the .part file so the next attempt can resume it
+		return nil, fmt.Errorf("failed to read PDF data from %s: %w", finalURL, err)
+	}
+	transferTime := time.Since(startTime)               // Request, headers and body, excluding the checks below
+	throughput := bytesPerSecond(written, transferTime) // Only the bytes fetched now, not a resumed prefix
+	if closeErr != nil {                                // Handle flush/close error
+		os.Remove(tempPath) // Discard the incomplete file
+		return nil, fmt.Errorf("failed to write PDF to file for %s: %w", finalURL, closeErr)
+	}
+
+	if resumeOffset > 0 { // Report the size of the whole file
+		downloader.logger().Debug("resumed download", "url", finalURL, "offset", resumeOffset)
+		written += resumeOffset
+	}
+
+	if downloader.MaxSize > 0 && written > downloader.MaxSize { // Missing or lying Content-Length, or a decoded body that grew
+		os.Remove(tempPath) // Discard the truncated file
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrTooLarge, finalURL, downloader.MaxSize)
+	}
+
+	if written == 0 { // If no bytes were written, discard the empty file
+		os.Remove(tempPath) // Remove the empty temporary file
+		return nil, fmt.Errorf("%w: downloaded 0 bytes for %s; not creating file", ErrZeroBytes, finalURL)
+	}
+
+	if written < downloader.MinSize { // Stub PDFs served in place of a missing document
+		os.Remove(tempPath) // Keep the placeholder off disk; any earlier copy stays
+		return nil, fmt.Errorf("%w: %s is %d bytes, minimum %d", ErrTooSmall, finalURL, written, downloader.MinSize)
+	}
+
+	contentHash := hex.EncodeToString(hasher.Sum(nil))                                            // Hex SHA-256 of the downloaded bytes
+	if expected, known := downloader.ExpectedHashes[finalURL]; known && expected != contentHash { // Corrupted or swapped file
+		if downloader.DeleteOnMismatch { // Keep the bad content off disk; any earlier copy stays
+			os.Remove(tempPath)
+			return nil, fmt.Errorf("%w: %s has sha256 %s, expected %s", ErrMismatch, finalURL, contentHash, expected)
+		}
+		downloader.logger().Error("checksum mismatch", "url", finalURL, "sha256", contentHash, "expected", expected)
+	}
+	duplicateOf, err := downloader.HashIndex.StoreUnique(contentHash, tempPath, filePath) // Move into place unless already saved
+	if err != nil {                                                                       // Handle rename or index write error
+		return nil, fmt.Errorf("failed to save PDF for %s: %w", finalURL, err)
+	}
+	record := &storage.DownloadRecord{ // Describe the completed download for the manifest
+		URL:          finalURL,
+		Filename:     filePath,
+		Size:         written,
+		SHA256:       contentHash,
+		HTTPStatus:   resp.StatusCode,
+		ContentType:  contentType,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		DownloadedAt: time.Now().UTC(),
+
+		DurationMS:     transferTime.Milliseconds(),
+		BytesPerSecond: throughput,
+	}
+	if label, labelled := downloader.label(finalURL); labelled { // Product name, category and revision date from the scraped page
+		record.LinkTitle = label.Title
+		record.Category = label.Category
+		record.RevisionDate = label.RevisionDate
+	}
+	if downloader.SlowThreshold > 0 && transferTime > downloader.SlowThreshold { // Worth a look when tuning workers and rate
+		downloader.logger().Warn("slow download", "url", finalURL, "bytes", written, "duration", transferTime.Round(time.Millisecond), "bytes_per_second", int64(throughput), "threshold", downloader.SlowThreshold)
+	}
+	savedPath := filePath  // Where the content now lives
+	if duplicateOf != "" { // The content lives in the earlier copy
+		savedPath = duplicateOf
+	}
+	if strings.EqualFold(filepath.Ext(savedPath), ".pdf") { // Other document types carry no PDF metadata
+		if err := extractPDFMetadata(savedPath, record); err != nil { // Encrypted or unreadable PDFs keep blank metadata
+			downloader.logger().Warn("failed to read PDF metadata", "url", finalURL, "file", savedPath, "error", err)
+		}
+	}
+	if duplicateOf != "" && downloader.HardlinkDuplicates { // Give the content this name too, without a second copy
+		if err := storage.LinkDuplicate(duplicateOf, filePath); err != nil {
+			downloader.logger().Warn("failed to hard-link duplicate content, skipping", "url", finalURL, "file", filePath, "original", duplicateOf, "error", err)
+		} else {
+			downloader.logger().Info("duplicate content, hard-linked", "url", finalURL, "file", filePath, "original", duplicateOf, "sha256", contentHash, "bytes", written, "duration", time.Since(startTime))
+			return record, nil
+		}
+	}
+	if duplicateOf != "" { // Identical content already exists under another name
+		downloader.logger().Log(ctx, storage.LevelSkip, "duplicate content, skipping", "url", finalURL, "file", filePath, "original", duplicateOf, "sha256", contentHash, "bytes", written, "duration", time.Since(startTime))
+		record.Filename = duplicateOf // Point the record at the existing copy
+		record.Duplicate = true       // so it doesn't claim that name from its owner
+		return record, nil
+	}
+
+	downloader.logger().Info("downloaded", "url", finalURL, "file", filePath, "bytes", written, "status", resp.StatusCode, "duration", time.Since(startTime)) // Log success
+	return record, nil
+}
+
+// bytesPerSecond returns the transfer rate, or 0 when elapsed is too small to measure
+func bytesPerSecond(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 { // Avoid dividing by zero on coarse clocks
+		return 0
+	}
+	return float64(bytes) / elapsed.Seconds()
+}
+
+// htmlSniffLength is how many leading bytes looksLikeHTML inspects
+const htmlSniffLength = 512
+
+// remoteIsLarger sends a HEAD request for finalURL and reports whether its Content-Length exceeds the
+// size of filePath; a failed request or a missing length keeps the local copy
+func (downloader *Downloader) remoteIsLarger(ctx context.Context, finalURL, filePath string) bool {
+	info, err := os.Stat(filePath) // Size of our copy
+	if err != nil {
+		return false
+	}
+	attemptCtx, cancelAttempt := downloader.attemptContext(ctx) // Bound the HEAD like a download attempt
+	defer cancelAttempt()
+	if err := downloader.RateLimiter.Wait(attemptCtx, finalURL); err != nil { // HEADs count against the host's rate too
+		return false
+	}
+	request, err := http.NewRequestWithContext(attemptCtx, http.MethodHead, finalURL, nil)
+	if err != nil {
+		return false
+	}
+	downloader.setAccept(request) // Ask about the file, not a landing page
+	resp, err := downloader.HTTPClient.Do(request)
+	if err != nil { // Leave the file alone; the next run can check again
+		downloader.logger().Warn("size check failed, keeping existing file", "url", finalURL, "error", err)
+		return false
+	}
+	resp.Body.Close()                                                // HEAD responses have no body worth reading
+	if resp.StatusCode != http.StatusOK || resp.ContentLength <= 0 { // No trustworthy size to compare
+		downloader.logger().Debug("no remote size to compare, keeping existing file", "url", finalURL, "status", resp.StatusCode)
+		return false
+	}
+	if resp.ContentLength <= info.Size() { // Same or smaller: likely the same file, or a truncated one
+		return false
+	}
+	downloader.logger().Info("remote file is larger, re-downloading", "url", finalURL, "file", filePath, "local_bytes", info.Size(), "remote_bytes", resp.ContentLength)
+	return true
+}
+
+// looksLikeHTML reports whether prefix starts like an HTML document, ignoring a BOM, whitespace and case
+func looksLikeHTML(prefix []byte) bool {
+	prefix = bytes.TrimPrefix(prefix, []byte("\xEF\xBB\xBF")) // UTF-8 byte order mark
+	prefix = bytes.ToLower(bytes.TrimSpace(prefix))           // Tags are case-insensitive
+	return bytes.HasPrefix(prefix, []byte("<!doctype html")) || bytes.HasPrefix(prefix, []byte("<html"))
+}
+
+// attemptContext derives the context for one download attempt, limited to DownloadTimeout when it's set
+func (downloader *Downloader) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if downloader.DownloadTimeout <= 0 { // No per-download deadline
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, downloader.DownloadTimeout)
+}
+
+// contentTypes returns the accepted media types, PDF only unless ContentTypes is set
+func (downloader *Downloader) contentTypes() []string {
+	if len(downloader.ContentTypes) == 0 { // Preserve the PDF-only default
+		return storage.DefaultContentTypes
+	}
+	return downloader.ContentTypes
+}
+
+// requestPDF sends the GET request for a PDF, asking for the bytes after offset when resuming
+// and making the request conditional on the validators in conditional when it's non-nil
+func (downloader *Downloader) requestPDF(ctx context.Context, finalURL string, offset int64, conditional *storage.DownloadRecord) (*http.Response, error) {
+	if err := downloader.RateLimiter.Wait(ctx, finalURL); err != nil { // Wait for this host's rate limit
+		return nil, fmt.Errorf("rate limit wait cancelled for %s: %w", finalURL, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, finalURL, nil) // Build a cancellable GET request
+	if err != nil {                                                                // Handle request construction error
+		return nil, fmt.Errorf("failed to build request for %s: %w", finalURL, err)
+	}
+	downloader.setAccept(request) // Some servers answer with an HTML landing page unless asked for the file
+	if offset > 0 {               // Ask only for the bytes we don't have yet
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if conditional != nil && conditional.ETag != "" { // Only send the body if the content changed
+		request.Header.Set("If-None-Match", conditional.ETag)
+	}
+	if conditional != nil && conditional.LastModified != "" { // Fallback for servers without ETags
+		request.Header.Set("If-Modified-Since", conditional.LastModified)
+	}
+
+	resp, err := downloader.HTTPClient.Do(request) // Send the request on the shared client
+	if err != nil {                                // Handle GET error
+		return nil, fmt.Errorf("failed to download %s: %w", finalURL, err)
+	}
+	return resp, nil
+}
+
+// setAccept adds the Accept header to a download request, when one is configured
+func (downloader *Downloader) setAccept(request *http.Request) {
+	if downloader.Accept != "" {
+		request.Header.Set("Accept", downloader.Accept)
+	}
+}
+
+// resumeAccepted returns true if the response continues the file from exactly offset
+func resumeAccepted(resp *http.Response, offset int64) bool {
+	if resp.StatusCode != http.StatusPartialContent { // Only 206 carries a byte range
+		return false
+	}
+	return strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) // Range must start where we stopped
+}
+
+// partialDownloadOffset returns the size of a resumable .part file, discarding it if it can't be resumed;
+// the file must start with signature when one is known
+func (downloader *Downloader) partialDownloadOffset(tempPath string, signature []byte) int64 {
+	info, err := os.Stat(tempPath) // Look for a leftover partial file
+	if err != nil {                // Nothing to resume
+		return 0
+	}
+	if downloader.Force || info.Size() < int64(max(len(signature), 1)) || !fileStartsWith(tempPath, signature) { // Not worth resuming
+		os.Remove(tempPath) // Start from scratch
+		return 0
+	}
+	return info.Size()
+}
+
+// fileStartsWith returns true if the file at path begins with prefix
+func fileStartsWith(path string, prefix []byte) bool {
+	file, err := os.Open(path) // Open the file for reading
+	if err != nil {            // Handle open error
+		return false
+	}
+	defer file.Close() // Ensure file is closed
+
+	head := make([]byte, len(prefix))                  // Buffer for the leading bytes
+	if _, err := io.ReadFull(file, head); err != nil { // File is shorter than the prefix
+		return false
+	}
+	return bytes.Equal(head, prefix)
+}
+
+// openPartialFile opens the temporary download file, feeding any already-downloaded bytes to hasher
+func openPartialFile(tempPath string, offset int64, hasher io.Writer) (*os.File, error) {
+	if offset == 0 { // Fresh download: start with an empty file
+		return os.Create(tempPath)
+	}
+
+	existing, err := os.Open(tempPath) // Reopen the partial file to hash what we already have
+	if err != nil {                    // Handle open error
+		return nil, err
+	}
+	_, err = io.CopyN(hasher, existing, offset) // Hash the bytes fetched by the earlier attempt
+	existing.Close()                            // Done reading the existing bytes
+	if err != nil {                             // Handle read error
+		return nil, err
+	}
+	return os.OpenFile(tempPath, os.O_WRONLY|os.O_APPEND, 0644) // Append the remaining bytes
+}
+
+// ErrBadStatus reports a download that completed with an unexpected HTTP status; match it with errors.As
+type ErrBadStatus struct {
+	URL    string // URL that was requested
+	Status string // Status line returned by the server
+	Code   int    // Numeric HTTP status code
+}
+
+// Error formats the status failure for logging
+func (statusErr *ErrBadStatus) Error() string {
+	return fmt.Sprintf("download failed for %s: %s", statusErr.URL, statusErr.Status)
+}
+
+// isRetryableDownloadError returns true if the error is transient and worth retrying within ctx, the
+// download's context. An expired ctx is final even though context.DeadlineExceeded satisfies net.Error:
+// the per-file deadline has passed, and another attempt would fail at once.
+func isRetryableDownloadError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil { // Out of time for this file, or a cancelled run
+		return false
+	}
+	if errors.Is(err, ErrStalled) { // The connection went quiet; another attempt resumes the .part file
+		return true
+	}
+	if errors.Is(err, context.Canceled) { // The run was cancelled
+		return false
+	}
+	var statusErr *ErrBadStatus
+	if errors.As(err, &statusErr) { // Server answered with an error status
+		return statusErr.Code >= 500 || statusErr.Code == http.StatusTooManyRequests // Only 5xx and 429 may recover
+	}
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) { // Untrusted or unpinned certificate; it won't change between attempts
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) { // Timeouts, connection resets, DNS failures, etc.
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) // Body cut off mid-transfer
+}
+
+// DownloadWithRetry calls DownloadPDF, retrying transient failures with exponential backoff
+func (downloader *Downloader) DownloadWithRetry(ctx context.Context, finalURL, filePath string, previous *storage.DownloadRecord, maxAttempts int) (*storage.DownloadRecord, error) {
+	backoff := time.Second // Initial delay before the first retry
+	var err error          // Last error returned by DownloadPDF
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ { // Try up to maxAttempts times
+		var record *storage.DownloadRecord                                      // Record of a successful download
+		record, err = downloader.DownloadPDF(ctx, finalURL, filePath, previous) // Attempt the download
+		if err == nil || !isRetryableDownloadError(ctx, err) || attempt == maxAttempts {
+			return record, err // Done on success, permanent failure, or exhausted attempts
+		}
+
+		delay := backoff + rand.N(backoff/2)                                                                                                                          // Add up to 50% jitter to the backoff
+		downloader.logger().Warn("download attempt failed, retrying", "url", finalURL, "attempt", attempt, "max_attempts", maxAttempts, "delay", delay, "error", err) // Log retry
+
+		select {
+		case <-ctx.Done(): // Stop retrying if the context is cancelled
+			return nil, fmt.Errorf("retry cancelled for %s: %w", finalURL, ctx.Err())
+		case <-time.After(delay): // Wait out the backoff
+		}
+		backoff *= 2 // Double the delay for the next attempt
+	}
+	return nil, err // Return the last error
+}
+
+// isUrlValid returns true if the given URL is valid
+func isUrlValid(uri string) bool {
+	_, err := url.ParseRequestURI(uri) // Attempt to parse URL string
+	return err == nil                  // Return true if no error, else false
+}
@@ -0,0 +1,177 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// testPDF is enough of a PDF to pass the signature check
+var testPDF = append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("0123456789abcdef\n"), 64)...)
+
+// newTestDownloader returns a Downloader for server that logs nowhere
+func newTestDownloader(server *httptest.Server) *Downloader {
+	downloader := New(server.Client())
+	downloader.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return downloader
+}
+
+// servePDF answers every request with testPDF as application/pdf
+func servePDF(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/pdf")
+	writer.Write(testPDF)
+}
+
+func TestDownloadPDFSavesPDF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(servePDF))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+
+	record, err := newTestDownloader(server).DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil)
+	if err != nil {
+		t.Fatalf("DownloadPDF: %v", err)
+	}
+	if record == nil || record.Filename != filePath || record.Size != int64(len(testPDF)) || record.HTTPStatus != http.StatusOK {
+		t.Errorf("record = %+v, want %s with %d bytes and status 200", record, filePath, len(testPDF))
+	}
+	saved, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("reading saved file: %v", err)
+	}
+	if !bytes.Equal(saved, testPDF) {
+		t.Errorf("saved %d bytes that differ from the %d served", len(saved), len(testPDF))
+	}
+}
+
+func TestDownloadPDFRejectsWrongContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/octet-stream")
+		writer.Write(testPDF)
+	}))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+
+	_, err := newTestDownloader(server).DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil)
+	if !errors.Is(err, ErrWrongContentType) {
+		t.Errorf("err = %v, want ErrWrongContentType", err)
+	}
+	if _, statErr := os.Stat(filePath); !os.IsNotExist(statErr) {
+		t.Errorf("file was saved despite the content type")
+	}
+}
+
+func TestDownloadPDFRejectsEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/pdf")
+	}))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+
+	_, err := newTestDownloader(server).DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil)
+	if !errors.Is(err, ErrZeroBytes) {
+		t.Errorf("err = %v, want ErrZeroBytes", err)
+	}
+	for _, path := range []string{filePath, filePath + ".part"} {
+		if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+			t.Errorf("%s exists after an empty download", filepath.Base(path))
+		}
+	}
+}
+
+func TestDownloadWithRetryGivesUpOnNotFound(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		http.NotFound(writer, request)
+	}))
+	defer server.Close()
+
+	_, err := newTestDownloader(server).DownloadWithRetry(context.Background(), server.URL+"/a.pdf", filepath.Join(t.TempDir(), "a.pdf"), nil, 3)
+	var statusErr *ErrBadStatus
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Fatalf("err = %v, want ErrBadStatus with code 404", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1: a 404 isn't worth retrying", got)
+	}
+}
+
+func TestDownloadWithRetryRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if requests.Add(1) == 1 { // The first attempt hits a struggling server
+			http.Error(writer, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		servePDF(writer, request)
+	}))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+
+	record, err := newTestDownloader(server).DownloadWithRetry(context.Background(), server.URL+"/a.pdf", filePath, nil, 3)
+	if err != nil {
+		t.Fatalf("DownloadWithRetry: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want 2", got)
+	}
+	if record == nil || record.Size != int64(len(testPDF)) {
+		t.Errorf("record = %+v, want %d bytes", record, len(testPDF))
+	}
+}
+
+func TestDownloadPDFResumesPartialFile(t *testing.T) {
+	half := len(testPDF) / 2
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"206 continues the file", func(writer http.ResponseWriter, request *http.Request) {
+			if request.Header.Get("Range") != fmt.Sprintf("bytes=%d-", half) {
+				t.Errorf("Range = %q, want bytes=%d-", request.Header.Get("Range"), half)
+			}
+			writer.Header().Set("Content-Type", "application/pdf")
+			writer.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(testPDF)-1, len(testPDF)))
+			writer.WriteHeader(http.StatusPartialContent)
+			writer.Write(testPDF[half:])
+		}},
+		{"200 ignores Range", servePDF},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+			filePath := filepath.Join(t.TempDir(), "a.pdf")
+			if err := os.WriteFile(filePath+".part", testPDF[:half], 0644); err != nil { // Left by an interrupted attempt
+				t.Fatal(err)
+			}
+
+			record, err := newTestDownloader(server).DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil)
+			if err != nil {
+				t.Fatalf("DownloadPDF: %v", err)
+			}
+			saved, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("reading saved file: %v", err)
+			}
+			if !bytes.Equal(saved, testPDF) {
+				t.Errorf("saved %d bytes that differ from the %d-byte file", len(saved), len(testPDF))
+			}
+			if record.Size != int64(len(testPDF)) {
+				t.Errorf("record.Size = %d, want %d", record.Size, len(testPDF))
+			}
+			if _, err := os.Stat(filePath + ".part"); !os.IsNotExist(err) {
+				t.Errorf(".part file left behind")
+			}
+		})
+	}
+}
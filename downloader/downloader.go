@@ -0,0 +1,115 @@
+// Package downloader fetches SDS PDFs: it resumes partial downloads, verifies content, retries
+// transient failures, and runs a worker pool that records every result in the storage package.
+// Fetch downloads a single document for programs that embed it.
+package downloader // PDF downloads
+
+import (
+	"log/slog"      // For the optional logger
+	"net/http"      // For the HTTP client used for downloads
+	"sync"          // For guarding labels added mid-run
+	"text/template" // For custom filename schemes
+	"time"          // For the download deadline and retry delay
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link store, manifest, and file helpers
+)
+
+// Downloader holds the shared state every download worker uses; HTTPClient, RateLimiter and
+// HashIndex are required, and New fills them in for library use
+type Downloader struct {
+	HTTPClient        *http.Client              // Client for every download request; its Timeout should be 0 so DownloadTimeout governs
+	RateLimiter       *HostRateLimiter          // Per-host request limiter shared by all workers
+	Jitter            time.Duration             // Random pause of up to this long before each download, so timing isn't uniform; 0 disables it
+	Shuffle           bool                      // Download links in random order instead of the order given
+	InterleaveHosts   bool                      // Take links round-robin across hosts so no host gets several downloads in a row
+	HashIndex         *storage.ContentHashIndex // Content hashes of previously saved PDFs
+	LinkFile          string                    // File that tracks already processed links
+	Database          string                    // SQLite database used instead of LinkFile when set
+	Force             bool                      // Re-download PDFs even if they already exist on disk
+	OverwriteIfLarger bool                      // Re-download an existing PDF when a HEAD request reports a larger Content-Length
+	MaxSize           int64                     // Largest PDF accepted in bytes; 0 disables the limit
+	MinSize           int64                     // Smallest PDF accepted in bytes, to catch stub placeholders; 0 disables the check
+	ContentTypes      []string                  // Media types accepted from the server; empty accepts only application/pdf
+	Accept            string                    // Accept header sent with download requests so content negotiation favors the file; empty sends none
+	MaxNew            int                       // Stop after this many new downloads, leaving the rest for the next run; 0 disables the limit
+
+	DownloadTimeout time.Duration // Deadline for one whole download attempt, body included; 0 disables it
+	StallTimeout    time.Duration // Abandon an attempt whose body delivers no data for this long, however much of DownloadTimeout is left; 0 disables it
+	FileTimeout     time.Duration // Hard deadline for one link across all its attempts, after which DownloadAll gives up on it; 0 disables it
+	SlowThreshold   time.Duration // Log downloads that take longer than this; 0 disables the warning
+	RetryFailed     bool          // Give transient failures one more pass at the end of DownloadAll
+	RetryDelay      time.Duration // Pause before that final pass
+	FailFast        bool          // Stop scheduling and cancel in-flight downloads at the first counted failure
+
+	NameTemplate *template.Template // Filename scheme from storage.ParseNameTemplate; nil uses the default names
+	Partition    string             // Subdirectory of the output directory for new files; empty disables partitioning
+
+	ExpectedHashes     map[string]string            // Known SHA-256 per URL to verify downloads against; nil skips verification
+	Labels             map[string]storage.LinkLabel // Page context per URL, copied into each record; nil records none. Use AddLabels once downloads have started
+	DeleteOnMismatch   bool                         // Discard downloads whose hash doesn't match instead of only logging
+	HardlinkDuplicates bool                         // Hard-link a file whose content an earlier file already holds, instead of skipping it
+
+	Logger        *slog.Logger                                            // Destination for the Downloader's logs; nil uses slog.Default()
+	OnResult      func(result string, bytes int64, elapsed time.Duration) // Called from the workers as DownloadAll settles each link, e.g. for metrics; nil disables it
+	PostProcessor PostProcessor                                           // Run by DownloadAll on every newly saved file, e.g. OCR or a virus scan; nil runs none
+
+	labelMutex sync.RWMutex // Guards Labels while DownloadStream's producers add to it
+}
+
+// Link outcomes passed to OnResult
+const (
+	ResultDownloaded       = "downloaded"        // A new or changed file was saved
+	ResultAlreadyPresent   = "already_present"   // The file was on disk or the server said it was unchanged
+	ResultAlreadyProcessed = "already_processed" // The link store had it and no request was made
+	ResultDeferred         = "deferred"          // Left for the next run by MaxNew
+	ResultFailed           = "failed"            // Every attempt failed
+	ResultAbandoned        = "abandoned"         // Cut short by shutdown
+)
+
+// New returns a Downloader that fetches through client with no rate limit and an in-memory hash index,
+// sending "Accept: application/pdf"
+func New(client *http.Client) *Downloader {
+	return &Downloader{
+		HTTPClient:  client,
+		RateLimiter: NewHostRateLimiter(0),
+		HashIndex:   storage.NewContentHashIndex(),
+		Accept:      "application/pdf",
+	}
+}
+
+// AddLabels merges labels into Labels, keeping any label a link already has; unlike setting Labels,
+// it's safe while downloads are running
+func (downloader *Downloader) AddLabels(labels map[string]storage.LinkLabel) {
+	downloader.labelMutex.Lock()
+	defer downloader.labelMutex.Unlock()
+	if downloader.Labels == nil {
+		downloader.Labels = make(map[string]storage.LinkLabel, len(labels))
+	}
+	for link, label := range labels {
+		if _, labelled := downloader.Labels[link]; !labelled { // The first page to describe a link wins
+			downloader.Labels[link] = label
+		}
+	}
+}
+
+// label returns the page context recorded for link, if any
+func (downloader *Downloader) label(link string) (storage.LinkLabel, bool) {
+	downloader.labelMutex.RLock()
+	defer downloader.labelMutex.RUnlock()
+	label, labelled := downloader.Labels[link]
+	return label, labelled
+}
+
+// report passes a link's outcome to OnResult, if set
+func (downloader *Downloader) report(result string, bytes int64, elapsed time.Duration) {
+	if downloader.OnResult != nil {
+		downloader.OnResult(result, bytes, elapsed)
+	}
+}
+
+// logger returns Logger, or the default logger when none was set
+func (downloader *Downloader) logger() *slog.Logger {
+	if downloader.Logger == nil {
+		return slog.Default()
+	}
+	return downloader.Logger
+}
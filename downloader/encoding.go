@@ -0,0 +1,40 @@
+package downloader // Decoding of compressed response bodies
+
+import (
+	"bufio"          // For sniffing the deflate wrapper
+	"compress/flate" // For raw deflate bodies
+	"compress/gzip"  // For gzip bodies
+	"compress/zlib"  // For zlib-wrapped deflate bodies
+	"fmt"            // For formatted error messages
+	"io"             // For reader plumbing
+	"net/http"       // For the response headers
+	"strings"        // For normalizing the encoding name
+)
+
+// contentEncoding returns the response's Content-Encoding, or "" for identity.
+// Go's transport only decodes gzip itself when it chose the Accept-Encoding header, so custom headers can leave bodies encoded.
+func contentEncoding(resp *http.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) // Encoding the server applied
+	if encoding == "identity" {                                                         // Explicitly unencoded
+		return ""
+	}
+	return encoding
+}
+
+// decodeBody wraps body so it yields the decoded bytes for the given Content-Encoding
+func decodeBody(body io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate": // Usually zlib-wrapped, but some servers send raw deflate
+		buffered := bufio.NewReader(body) // Peek at the header without consuming it
+		header, _ := buffered.Peek(2)     // zlib streams start with a two-byte header
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
@@ -0,0 +1,39 @@
+package downloader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadPDFDecodesGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	gzipWriter.Write(testPDF)
+	gzipWriter.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/pdf")
+		writer.Header().Set("Content-Encoding", "gzip")
+		writer.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	downloader := newTestDownloader(server)
+	downloader.HTTPClient = &http.Client{Transport: &http.Transport{DisableCompression: true}} // Leave the body encoded, as custom Accept-Encoding headers do
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+	if _, err := downloader.DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil); err != nil {
+		t.Fatalf("DownloadPDF: %v", err)
+	}
+	saved, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("reading saved file: %v", err)
+	}
+	if !bytes.Equal(saved, testPDF) {
+		t.Errorf("saved %d bytes, want the %d-byte plain PDF", len(saved), len(testPDF))
+	}
+}
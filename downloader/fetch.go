@@ -0,0 +1,41 @@
+package downloader // Library entry point for downloading one document
+
+import (
+	"context"  // For cancelling the download
+	"errors"   // For the already-downloaded sentinel
+	"fmt"      // For wrapping errors
+	"net/http" // For the default client
+	"os"       // For creating the destination directory
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Download records and file names
+)
+
+// Record describes one completed download: where it was saved, its size, SHA-256 and HTTP validators
+type Record = storage.DownloadRecord
+
+// ErrAlreadyDownloaded reports that the destination file already exists and Force isn't set
+var ErrAlreadyDownloaded = errors.New("already downloaded")
+
+// Fetch downloads link into dir with a Downloader from New(http.DefaultClient); see Downloader.Fetch
+func Fetch(ctx context.Context, link, dir string) (Record, error) {
+	return New(http.DefaultClient).Fetch(ctx, link, dir)
+}
+
+// Fetch downloads link into dir, creating dir if needed, with the same validation as a full run:
+// content type, file signature, size limits and, when ExpectedHashes has the link, its checksum.
+// The file is named like the command names it, honouring NameTemplate. A file that already exists
+// is left alone and reported with an error wrapping ErrAlreadyDownloaded unless Force is set.
+func (downloader *Downloader) Fetch(ctx context.Context, link, dir string) (Record, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Record{}, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	filePath := storage.NewFilenameRegistry(nil, downloader.NameTemplate, "").PathFor(dir, link) // Where the command would save it
+	record, err := downloader.DownloadPDF(ctx, link, filePath, nil)
+	if err != nil {
+		return Record{}, err
+	}
+	if record == nil { // DownloadPDF skips files that are already on disk
+		return Record{}, fmt.Errorf("%w: %s", ErrAlreadyDownloaded, filePath)
+	}
+	return *record, nil
+}
@@ -0,0 +1,128 @@
+package downloader // Document metadata read from downloaded PDFs
+
+import (
+	"fmt"     // For formatted error messages
+	"strconv" // For PDF date time zone offsets
+	"strings" // For cleaning up PDF date strings
+	"time"    // For PDF creation dates
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link store, manifest, and file helpers
+	"github.com/ledongthuc/pdf"                                        // Pure-Go PDF reader
+)
+
+// pdfDateLayouts are the precisions of the PDF date syntax D:YYYYMMDDHHmmSSOHH'mm, most specific first.
+// The time zone suffix is split off by pdfDateZone before parsing.
+var pdfDateLayouts = []string{
+	"20060102150405",
+	"200601021504",
+	"2006010215",
+	"20060102",
+	"200601",
+	"2006",
+}
+
+// extractPDFMetadata fills the record's title, author, page count, and creation date from the PDF at path.
+// The fields are left blank if the file is encrypted or can't be parsed.
+func extractPDFMetadata(path string, record *storage.DownloadRecord) (err error) {
+	defer func() { // The PDF reader panics on some malformed files
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("failed to parse PDF %s: %v", path, recovered)
+		}
+	}()
+
+	file, reader, err := pdf.Open(path) // Open and parse the cross-reference table
+	if err != nil {                     // Encrypted with a password, or not a well-formed PDF
+		return fmt.Errorf("failed to open PDF %s: %w", path, err)
+	}
+	defer file.Close() // Ensure file is closed
+
+	info := reader.Trailer().Key("Info") // Document information dictionary
+	title := info.Key("Title").Text()    // Document title
+	author := info.Key("Author").Text()  // Document author
+	pages := reader.NumPage()            // Page count from the page tree
+	created := parsePDFDate(info.Key("CreationDate").Text())
+
+	record.Title = strings.TrimSpace(title) // Only fill the record once everything parsed
+	record.Author = strings.TrimSpace(author)
+	record.Pages = pages
+	record.CreatedAt = created
+	return nil
+}
+
+// ValidatePDF opens the PDF at path with a full parser and loads every page, returning the page count;
+// it catches files that start with %PDF- but are truncated or corrupt
+func ValidatePDF(path string) (pages int, err error) {
+	defer func() { // The PDF reader panics on some malformed files
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("failed to parse PDF %s: %v", path, recovered)
+		}
+	}()
+
+	file, reader, err := pdf.Open(path) // Needs the cross-reference table at the end of the file
+	if err != nil {
+		return 0, fmt.Errorf("failed to open PDF %s: %w", path, err)
+	}
+	defer file.Close() // Ensure file is closed
+
+	pages = reader.NumPage() // Page count from the page tree
+	if pages == 0 {          // An SDS always has at least one page
+		return 0, fmt.Errorf("PDF %s has no pages", path)
+	}
+	for pageNumber := 1; pageNumber <= pages; pageNumber++ { // Every page object must resolve
+		if reader.Page(pageNumber).V.IsNull() {
+			return 0, fmt.Errorf("PDF %s is missing page %d of %d", path, pageNumber, pages)
+		}
+	}
+	return pages, nil
+}
+
+// parsePDFDate parses a PDF date string such as "D:20230115093000+01'00'", returning nil if it's malformed
+func parsePDFDate(text string) *time.Time {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "D:") // The prefix is optional
+	if text == "" {                                          // No date recorded
+		return nil
+	}
+	digits, zone, ok := pdfDateZone(text)
+	if !ok {
+		return nil
+	}
+	for _, layout := range pdfDateLayouts { // Try each allowed precision
+		if parsed, err := time.ParseInLocation(layout, digits, zone); err == nil {
+			parsed = parsed.UTC() // Store dates consistently in UTC
+			return &parsed
+		}
+	}
+	return nil
+}
+
+// pdfDateZone splits a PDF date into its digits and the zone given by its Z, +HH'mm', or -HH'mm' suffix.
+// Dates without a suffix are taken as UTC, as is Z with any trailing offset (Adobe writes Z00'00').
+func pdfDateZone(text string) (string, *time.Location, bool) {
+	index := strings.IndexAny(text, "Z+-")
+	if index < 0 { // No zone recorded
+		return text, time.UTC, true
+	}
+	digits, suffix := text[:index], text[index:]
+	if suffix[0] == 'Z' {
+		return digits, time.UTC, true
+	}
+	offset := strings.ReplaceAll(suffix[1:], "'", "") // HH'mm' becomes HHmm
+	if len(offset) != 2 && len(offset) != 4 {         // Minutes are optional
+		return "", nil, false
+	}
+	hours, err := strconv.Atoi(offset[:2])
+	if err != nil || hours > 23 {
+		return "", nil, false
+	}
+	minutes := 0
+	if len(offset) == 4 {
+		if minutes, err = strconv.Atoi(offset[2:]); err != nil || minutes > 59 {
+			return "", nil, false
+		}
+	}
+	seconds := (hours*60 + minutes) * 60
+	if suffix[0] == '-' {
+		seconds = -seconds
+	}
+	return digits, time.FixedZone("", seconds), true
+}
@@ -0,0 +1,39 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePDFDate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // RFC 3339 in UTC, or "" for nil
+	}{
+		{"D:20230115093000Z00'00'", "2023-01-15T09:30:00Z"},
+		{"D:20230115093000Z", "2023-01-15T09:30:00Z"},
+		{"D:20230115093000+05'30'", "2023-01-15T04:00:00Z"},
+		{"D:20230115093000-08'00'", "2023-01-15T17:30:00Z"},
+		{"D:20230115093000+01'00", "2023-01-15T08:30:00Z"},
+		{"D:20230115093000+0130", "2023-01-15T08:00:00Z"},
+		{"D:20230115093000-05", "2023-01-15T14:30:00Z"},
+		{"20230115093000", "2023-01-15T09:30:00Z"},
+		{"D:20230115", "2023-01-15T00:00:00Z"},
+		{"D:2023", "2023-01-01T00:00:00Z"},
+		{"", ""},
+		{"D:yesterday", ""},
+		{"D:20230115093000+5'30'", ""},
+		{"D:20230115093000+25'00'", ""},
+	}
+	for _, test := range tests {
+		got := parsePDFDate(test.text)
+		switch {
+		case test.want == "" && got != nil:
+			t.Errorf("parsePDFDate(%q) = %v, want nil", test.text, got)
+		case test.want != "" && got == nil:
+			t.Errorf("parsePDFDate(%q) = nil, want %s", test.text, test.want)
+		case got != nil && got.Format(time.RFC3339) != test.want:
+			t.Errorf("parsePDFDate(%q) = %s, want %s", test.text, got.Format(time.RFC3339), test.want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package downloader // Worker pool that downloads every discovered link
+
+import (
+	"context"       // For managing deadlines, cancellation signals, etc.
+	"errors"        // For recognizing discarded files and expired deadlines
+	"fmt"           // For wrapping per-file timeouts
+	"math/rand/v2"  // For shuffling the download order
+	"net/url"       // For grouping jobs by host
+	"os"            // For removing stalled partial downloads
+	"path/filepath" // For manipulating file system paths
+	"sync"          // For goroutine synchronization primitives
+	"time"          // For working with time durations and timestamps
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link store, manifest, and file helpers
+)
+
+// ErrFileTimeout is the failure DownloadAll records for a link it gave up on after FileTimeout
+var ErrFileTimeout = errors.New("file timed out")
+
+// downloadJob is one link handed to a download worker
+type downloadJob struct {
+	url      string // Source URL of the PDF
+	filePath string // Where the PDF is saved
+}
+
+// DownloadAll downloads every link using a bounded pool of worker goroutines and summarizes the outcome
+func (downloader *Downloader) DownloadAll(ctx context.Context, links []string, outputDir string, workers, maxAttempts int, quiet bool) Summary {
+	return downloader.download(ctx, outputDir, workers, maxAttempts, quiet, func(registry *storage.FilenameRegistry) (<-chan downloadJob, int) {
+		jobs := make([]downloadJob, len(links)) // Every link with its destination path
+		for linkIndex, link := range links {
+			jobs[linkIndex] = downloadJob{url: link, filePath: registry.PathFor(outputDir, link)}
+		}
+		if downloader.Shuffle { // Shuffle after naming, so filename collisions resolve the same way as in page order
+			rand.Shuffle(len(jobs), func(i, j int) { jobs[i], jobs[j] = jobs[j], jobs[i] })
+		}
+		if downloader.InterleaveHosts { // Spread each host's downloads out instead of hitting it back-to-back
+			jobs = interleaveByHost(jobs)
+		}
+		return feedJobs(jobs), len(jobs)
+	})
+}
+
+// DownloadStream is DownloadAll for links that are still being discovered: workers start on each link as
+// it arrives and the run ends once links is closed and drained. A link seen before is dropped, however
+// many producers send it. Shuffle and InterleaveHosts don't apply, since the order isn't known upfront.
+func (downloader *Downloader) DownloadStream(ctx context.Context, links <-chan string, outputDir string, workers, maxAttempts int, quiet bool) Summary {
+	summary := downloader.download(ctx, outputDir, workers, maxAttempts, quiet, func(registry *storage.FilenameRegistry) (<-chan downloadJob, int) {
+		jobs := make(chan downloadJob)
+		go func() {
+			defer close(jobs)
+			seen := make(map[string]bool) // Shared by every producer, since they all feed this one loop
+			for link := range links {
+				if seen[link] { // Found again by another seed or the sitemap
+					continue
+				}
+				seen[link] = true
+				jobs <- downloadJob{url: link, filePath: registry.PathFor(outputDir, link)} // Named in arrival order
+			}
+		}()
+		return jobs, 0
+	})
+	for range links { // Unblock the producers if the run ended before reading everything, e.g. an unreadable link store
+	}
+	return summary
+}
+
+// feedJobs returns a closed-when-done channel that yields jobs in order
+func feedJobs(jobs []downloadJob) <-chan downloadJob {
+	jobChannel := make(chan downloadJob, len(jobs)) // Buffered so it can be filled without a goroutine
+	for _, job := range jobs {
+		jobChannel <- job
+	}
+	close(jobChannel)
+	return jobChannel
+}
+
+// download runs the worker pool over the jobs queue yields, with queue's total for the progress
+// indicator (0 when it isn't known), then retries transient failures and writes the manifest
+func (downloader *Downloader) download(ctx context.Context, outputDir string, workers, maxAttempts int, quiet bool, queue func(*storage.FilenameRegistry) (<-chan downloadJob, int)) Summary {
+	summary := Summary{}                     // Outcome counters for the run
+	ctx, abortRun := context.WithCancel(ctx) // Cancelled early by FailFast
+	defer abortRun()
+
+	trackedLinks, err := storage.OpenLinkIndex(downloader.Database, downloader.LinkFile) // Read previously processed PDF links
+	if err != nil {                                                                      // Don't overwrite a store we couldn't read
+		downloader.logger().Error("failed to load link store", "error", err)
+		return summary
+	}
+	defer trackedLinks.Close() // Release the database, if one is used
+
+	manifestPath := filepath.Join(outputDir, storage.ManifestFile)     // Manifest lives next to the PDFs
+	previousRecords, manifestErr := storage.ReadManifest(manifestPath) // Keep records from earlier runs
+	if manifestErr != nil {                                            // Don't clobber a manifest we couldn't read
+		downloader.logger().Error("failed to read manifest", "error", manifestErr)
+	}
+	registry := storage.NewFilenameRegistry(previousRecords, downloader.NameTemplate, downloader.Partition) // Tracks which URL owns each filename
+	previousByURL := storage.RecordsByURL(previousRecords)                                                  // Validators from earlier downloads, for conditional GETs
+
+	var countMutex sync.Mutex                  // Guards the summary, abandoned counter, records, and retry queue
+	abandonedCount := 0                        // Number of in-flight downloads cut short by shutdown
+	inFlightCount := 0                         // Downloads currently running, counted against MaxNew
+	budgetChanged := sync.NewCond(&countMutex) // Signalled whenever a download finishes
+	var records []storage.DownloadRecord       // Manifest records for PDFs saved in this run
+
+	// runPass downloads the jobs from the channel with the worker pool until it's closed; with queueRetries,
+	// transient failures are returned for another pass instead of being counted, otherwise every failure is
+	// counted and returned. It also returns how many jobs it received, scheduled or not.
+	runPass := func(jobs <-chan downloadJob, total int, queueRetries bool) (failed []downloadJob, received, unscheduled int) {
+		progress := newProgressReporter(total, quiet) // Reports progress through this pass
+		linkChannel := make(chan downloadJob)         // Channel used to hand links to workers
+		var waitGroup sync.WaitGroup                  // Wait group to track running workers
+
+		for workerIndex := 0; workerIndex < workers; workerIndex++ { // Start the requested number of workers
+			waitGroup.Add(1) // Register the worker with the wait group
+			go func() {
+				defer waitGroup.Done() // Mark the worker as finished on exit
+
+				for job := range linkChannel { // Process links until the channel is closed
+					link := job.url // Source URL of this job
+
+					previous := previousByURL[link]                                                                                                                          // Earlier download of this link, if any
+					if !downloader.Force && !downloader.OverwriteIfLarger && !previous.CanRevalidate() && trackedLinks.Processed(link) && storage.FileExists(job.filePath) { // Skip already processed links we can't cheaply revalidate
+						downloader.logger().Log(ctx, storage.LevelSkip, "link already processed, skipping", "url", link, "file", job.filePath) // Log skip info
+						countMutex.Lock()
+						summary.AlreadyProcessed++
+						countMutex.Unlock()
+						downloader.report(ResultAlreadyProcessed, 0, 0)
+						progress.finish(job.filePath, 0) // Still counts towards overall progress
+						continue                         // Move to next link
+					}
+
+					countMutex.Lock() // Check the -max-new budget
+					for downloader.MaxNew > 0 && summary.Downloaded < downloader.MaxNew && summary.Downloaded+inFlightCount >= downloader.MaxNew {
+						budgetChanged.Wait() // In-flight downloads may still turn out not to be new
+					}
+					if downloader.MaxNew > 0 && summary.Downloaded >= downloader.MaxNew { // Budget spent
+						if summary.Deferred == 0 { // Say so once
+							downloader.logger().Info("new download limit reached, leaving remaining links for the next run", "max_new", downloader.MaxNew)
+						}
+						summary.Deferred++
+						countMutex.Unlock()
+						downloader.report(ResultDeferred, 0, 0)
+						progress.finish(job.filePath, 0) // Still counts towards overall progress
+						continue                         // Not recorded, so the next run picks it up
+					}
+					inFlightCount++ // Claim a slot of the budget
+					countMutex.Unlock()
+
+					if err := waitJitter(ctx, downloader.Jitter); err != nil { // Vary the timing between downloads; the abandon check below handles shutdown
+						downloader.logger().Debug("jitter wait cancelled", "url", link)
+					}
+
+					downloadStart := time.Now()                               // For OnResult's elapsed time
+					fileCtx, cancelFile := downloader.fileContext(ctx)        // One stalled link mustn't hold the worker forever
+					var record *storage.DownloadRecord                        // Record of the saved file, if any
+					err := downloader.recoverPanic(link, func() (err error) { // A panic fails this link, not the run
+						record, err = downloader.DownloadWithRetry(fileCtx, link, job.filePath, previous, maxAttempts) // Attempt to download the PDF file
+						return err
+					})
+					if err != nil && ctx.Err() == nil && errors.Is(fileCtx.Err(), context.DeadlineExceeded) { // The link ran out of time, not the run
+						os.Remove(job.filePath + ".part") // Don't resume a download that stalled
+						err = fmt.Errorf("%w: %s after %s", ErrFileTimeout, link, downloader.FileTimeout)
+					}
+					cancelFile()
+					elapsed := time.Since(downloadStart)                                // Every attempt, retries included
+					var postErr error                                                   // Failure of the post-processor on a file that was kept
+					if err == nil && record != nil && record.Filename == job.filePath { // A new file landed; duplicates were processed when first saved
+						postErr = downloader.postProcess(ctx, record)
+						if errors.Is(postErr, ErrDiscard) { // Deleted, so the download counts as failed
+							record, err, postErr = nil, postErr, nil
+						}
+					}
+					countMutex.Lock()                                    // Lock before updating the counters
+					inFlightCount--                                      // Release the slot; a success is now in Downloaded
+					budgetChanged.Broadcast()                            // Wake workers waiting on the budget
+					if err != nil && record == nil && ctx.Err() != nil { // Interrupted by shutdown rather than a real failure
+						downloader.logger().Warn("download abandoned on shutdown", "url", link) // Cut short by the interrupt
+						abandonedCount++
+						countMutex.Unlock()
+						downloader.report(ResultAbandoned, 0, elapsed)
+						progress.finish(job.filePath, 0) // Still counts towards overall progress
+						continue                         // Don't record the link so it's retried next run
+					}
+					if err != nil && queueRetries && isRetryableDownloadError(ctx, err) { // Try again once the rest of the run is done
+						downloader.logger().Warn("download failed, queued for retry", "url", link, "error", err)
+						failed = append(failed, job)
+						countMutex.Unlock()
+						progress.finish(job.filePath, 0) // Still counts towards this pass's progress
+						continue                         // Leave the link unrecorded until the retry pass
+					}
+					result := ResultDownloaded // Outcome for OnResult
+					switch {
+					case err != nil: // Handle download failure
+						result = ResultFailed
+						downloader.logger().Error("download failed", "url", link, "error", err) // Log the failure and keep going
+						summary.addFailure(link, err)
+						if !queueRetries { // Report it as still failing
+							failed = append(failed, job)
+						}
+						if downloader.FailFast && summary.Failed() == 1 { // Abort once, on the first failure
+							downloader.logger().Warn("fail fast: aborting the run after the first failed download", "url", link)
+							abortRun()
+						}
+					case record == nil: // Already on disk or not modified
+						result = ResultAlreadyPresent
+						summary.AlreadyPresent++
+					default:
+						summary.Downloaded++
+						summary.BytesWritten += record.Size
+						summary.addThroughput(record.BytesPerSecond)
+						summary.NewFiles = append(summary.NewFiles, record.Filename)
+						if postErr != nil { // Saved, but the post-processor failed on it
+							downloader.logger().Error("post-processing failed", "url", link, "file", record.Filename, "error", postErr)
+							summary.addFailure(link, postErr)
+						}
+					}
+					if record != nil { // Keep the record of anything actually fetched
+						records = append(records, *record)
+					}
+					countMutex.Unlock() // Release the counter lock
+
+					var downloadedBytes int64 // Size of the fetched file, if any
+					if record != nil {
+						downloadedBytes = record.Size
+					}
+					progress.finish(job.filePath, downloadedBytes) // Report overall progress
+					downloader.report(result, downloadedBytes, elapsed)
+
+					if err != nil { // Failed links are tried again next run and get their first-seen time then
+						continue
+					}
+					if trackedLinks.Processed(link) && downloadedBytes == 0 { // Nothing new to record
+						continue // Move to next link
+					}
+
+					if isUrlValid(link) { // Check if the final URL is a valid URL
+						if err := trackedLinks.MarkProcessed(link, record, time.Now().UTC()); err != nil { // Record the link
+							downloader.logger().Error("failed to update link store", "url", link, "error", err)
+						}
+					}
+				}
+			}()
+		}
+
+	feedLoop:
+		for job := range jobs { // Feed every job to the workers
+			received++
+			select {
+			case <-ctx.Done(): // Stop scheduling new work once cancelled
+				unscheduled = 1  // This job
+				for range jobs { // and whatever the queue still holds
+					unscheduled++
+				}
+				received += unscheduled - 1
+				downloader.logger().Warn("shutting down, not scheduling remaining links", "remaining", unscheduled)
+				break feedLoop
+			case linkChannel <- job: // Hand the job to the next free worker
+			}
+		}
+		close(linkChannel) // Signal workers that no more links are coming
+		waitGroup.Wait()   // Wait for all workers to finish
+		progress.stop()    // End the progress line
+		return failed, received, unscheduled
+	}
+
+	jobs, total := queue(registry)                                                        // Every link with its destination path
+	retryJobs, received, unscheduledCount := runPass(jobs, total, downloader.RetryFailed) // Main pass over every link
+	summary.LinksFound = received                                                         // The workers are done, so no lock is needed
+	if downloader.RetryFailed && len(retryJobs) > 0 {                                     // Give transient failures one more chance
+		downloader.logger().Info("retrying failed downloads", "count", len(retryJobs), "delay", downloader.RetryDelay)
+		select {
+		case <-ctx.Done(): // Interrupted before the retry pass started
+			unscheduledCount += len(retryJobs)
+		case <-time.After(downloader.RetryDelay): // Let a rate-limit burst clear
+			stillFailing, _, unscheduled := runPass(feedJobs(retryJobs), len(retryJobs), false) // Final pass; failures now count
+			unscheduledCount += unscheduled
+			if len(stillFailing) > 0 { // Name the stragglers that never made it
+				failedURLs := make([]string, len(stillFailing))
+				for jobIndex, job := range stillFailing {
+					failedURLs[jobIndex] = job.url
+				}
+				downloader.logger().Warn("downloads still failing after retry", "count", len(failedURLs), "urls", failedURLs)
+			}
+		}
+	}
+
+	if err := trackedLinks.Flush(); err != nil { // Make sure every completed download is on disk before exiting
+		downloader.logger().Error("failed to flush link store", "error", err)
+	}
+
+	if ctx.Err() != nil { // Report what the interrupt cut short
+		downloader.logger().Warn("shutdown complete", "abandoned_in_flight", abandonedCount, "not_started", unscheduledCount)
+	}
+
+	if manifestErr != nil { // Don't clobber a manifest we couldn't read
+		return summary
+	}
+	if err := storage.WriteManifest(storage.MergeManifest(previousRecords, records), manifestPath); err != nil { // Write the updated manifest
+		downloader.logger().Error("failed to write manifest", "error", err)
+	}
+	return summary
+}
+
+// fileContext derives the context for all of one link's attempts, limited to FileTimeout when it's set
+func (downloader *Downloader) fileContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if downloader.FileTimeout <= 0 { // No per-file deadline
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, downloader.FileTimeout)
+}
+
+// interleaveByHost reorders jobs round-robin across their hosts, taking hosts in order of first
+// appearance and keeping each host's jobs in their original order
+func interleaveByHost(jobs []downloadJob) []downloadJob {
+	var hosts []string                       // Hosts in order of first appearance
+	byHost := make(map[string][]downloadJob) // Host -> its jobs, in order
+	for _, job := range jobs {
+		host := job.url
+		if parsedURL, err := url.Parse(job.url); err == nil { // Unparseable URLs get a group of their own
+			host = parsedURL.Host
+		}
+		if _, seen := byHost[host]; !seen {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], job)
+	}
+
+	interleaved := make([]downloadJob, 0, len(jobs)) // One job per host per round
+	for round := 0; len(interleaved) < len(jobs); round++ {
+		for _, host := range hosts {
+			if round < len(byHost[host]) { // This host still has jobs left
+				interleaved = append(interleaved, byHost[host][round])
+			}
+		}
+	}
+	return interleaved
+}
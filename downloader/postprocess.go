@@ -0,0 +1,54 @@
+package downloader // Hook for post-processing each file DownloadAll saves
+
+import (
+	"context" // For cancelling the post-processor
+	"errors"  // For the post-processing sentinels
+	"fmt"     // For wrapping post-processor errors
+	"os"      // For removing discarded files
+)
+
+// PostProcessor runs on each file DownloadAll saves, e.g. to OCR or virus-scan it. Process is called on
+// the worker that downloaded the file, once it's in place under filePath. An error is logged and counted
+// as a failure but keeps the file; wrap ErrDiscard in it to have the file deleted as well.
+type PostProcessor interface {
+	Process(ctx context.Context, filePath string, record Record) error
+}
+
+// Post-processing errors; match them with errors.Is
+var (
+	ErrPostProcess = errors.New("post-processing failed") // Wrapped around every error a PostProcessor returns
+	ErrDiscard     = errors.New("discarded")              // Returned by a PostProcessor to have the file deleted
+)
+
+// NopPostProcessor is the PostProcessor used when none is set; it accepts every file
+type NopPostProcessor struct{}
+
+// Process does nothing
+func (NopPostProcessor) Process(context.Context, string, Record) error {
+	return nil
+}
+
+// postProcessor returns PostProcessor, or NopPostProcessor when none was set
+func (downloader *Downloader) postProcessor() PostProcessor {
+	if downloader.PostProcessor == nil {
+		return NopPostProcessor{}
+	}
+	return downloader.PostProcessor
+}
+
+// postProcess runs the post-processor on a newly saved file, deleting it when the error wraps ErrDiscard;
+// the returned error wraps ErrPostProcess
+func (downloader *Downloader) postProcess(ctx context.Context, record *Record) error {
+	err := downloader.recoverPanic(record.URL, func() error { // A panicking processor fails this file, not the run
+		return downloader.postProcessor().Process(ctx, record.Filename, *record)
+	})
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrDiscard) { // The processor doesn't want the file kept
+		if removeErr := os.Remove(record.Filename); removeErr != nil {
+			downloader.logger().Error("failed to remove discarded file", "url", record.URL, "file", record.Filename, "error", removeErr)
+		}
+	}
+	return fmt.Errorf("%w for %s: %w", ErrPostProcess, record.Filename, err)
+}
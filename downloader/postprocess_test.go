@@ -0,0 +1,47 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// panickingProcessor panics on files whose name contains panicOn and accepts the rest
+type panickingProcessor struct {
+	panicOn string
+}
+
+func (processor panickingProcessor) Process(ctx context.Context, filePath string, record Record) error {
+	if strings.Contains(filePath, processor.panicOn) {
+		panic("processor bug")
+	}
+	return nil
+}
+
+func TestDownloadAllSurvivesPanickingPostProcessor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/pdf")
+		writer.Write(append(testPDF, request.URL.Path...)) // Different content per file, so neither is a duplicate
+	}))
+	defer server.Close()
+	outputDir := t.TempDir()
+	downloader := newTestDownloader(server)
+	downloader.LinkFile = filepath.Join(t.TempDir(), "links.txt")
+	downloader.PostProcessor = panickingProcessor{panicOn: "bad"}
+
+	links := []string{server.URL + "/bad.pdf", server.URL + "/good.pdf"}
+	summary := downloader.DownloadAll(context.Background(), links, outputDir, 2, 1, true)
+
+	if summary.Downloaded != 2 {
+		t.Errorf("Downloaded = %d, want 2: the panic mustn't stop the other file", summary.Downloaded)
+	}
+	if summary.Failures["panic"] != 1 || summary.Failed() != 1 {
+		t.Errorf("Failures = %v, want one panic", summary.Failures)
+	}
+	if len(summary.FailureDetails) != 1 || summary.FailureDetails[0].URL != links[0] {
+		t.Errorf("FailureDetails = %+v, want %s", summary.FailureDetails, links[0])
+	}
+}
@@ -0,0 +1,103 @@
+package downloader // Overall progress reporting for the download loop
+
+import (
+	"fmt"           // For formatting progress lines
+	"io"            // For the progress destination
+	"log/slog"      // For periodic progress lines on non-terminals
+	"os"            // For detecting an interactive terminal
+	"path/filepath" // For showing just the file name
+	"sync"          // For guarding the counter across workers
+)
+
+// progressReporter prints "[done/total]" lines as downloads finish
+type progressReporter struct {
+	mutex       sync.Mutex // Guards done
+	output      io.Writer  // Where interactive progress is drawn
+	total       int        // Number of links queued; 0 when links are still being discovered
+	done        int        // Number of links finished so far
+	interactive bool       // True to redraw a single line on a terminal
+	quiet       bool       // True to print nothing
+	logEvery    int        // On non-terminals, log every this many completions
+}
+
+// newProgressReporter creates a reporter for total links, or an open-ended count when total is 0,
+// drawing on stderr when it's a terminal
+func newProgressReporter(total int, quiet bool) *progressReporter {
+	logEvery := total / 10 // Roughly ten progress lines per run on non-terminals
+	if total == 0 {        // No idea how long the run is; log every tenth link
+		logEvery = 10
+	}
+	if logEvery < 1 {
+		logEvery = 1
+	}
+	return &progressReporter{
+		output:      os.Stderr,
+		total:       total,
+		interactive: isTerminal(os.Stderr),
+		quiet:       quiet,
+		logEvery:    logEvery,
+	}
+}
+
+// finish records one completed link and reports progress
+func (progress *progressReporter) finish(filePath string, bytes int64) {
+	if progress.quiet { // Progress is suppressed
+		return
+	}
+
+	progress.mutex.Lock()         // Serialize updates across workers
+	defer progress.mutex.Unlock() // Release the lock when done
+
+	progress.done++                                                                           // Count this link
+	line := fmt.Sprintf("[%d/%d] %s", progress.done, progress.total, filepath.Base(filePath)) // e.g. [12/57] foo.pdf
+	if progress.total == 0 {                                                                  // Links are still arriving
+		line = fmt.Sprintf("[%d] %s", progress.done, filepath.Base(filePath))
+	}
+	if bytes > 0 { // Include the size when something was fetched
+		line += fmt.Sprintf(" (%s)", FormatByteSize(bytes))
+	}
+
+	if progress.interactive { // Redraw a single status line
+		fmt.Fprintf(progress.output, "\r\033[K%s", line)
+		if progress.done == progress.total { // Leave the final line in place
+			fmt.Fprintln(progress.output)
+		}
+		return
+	}
+	if progress.done%progress.logEvery == 0 || progress.done == progress.total { // Periodic lines for logs
+		slog.Info("progress", "done", progress.done, "total", progress.total, "file", filepath.Base(filePath))
+	}
+}
+
+// stop ends an interactive status line that didn't reach the total, e.g. after cancellation
+func (progress *progressReporter) stop() {
+	progress.mutex.Lock()         // Serialize with in-flight updates
+	defer progress.mutex.Unlock() // Release the lock when done
+
+	if !progress.quiet && progress.interactive && progress.done > 0 && (progress.done < progress.total || progress.total == 0) {
+		fmt.Fprintln(progress.output) // Move past the partial status line
+	}
+}
+
+// FormatByteSize formats a byte count with one decimal in the largest unit it reaches, e.g. "1.4 MB";
+// progress lines, the mirror index and run reports all use it so sizes read the same everywhere
+func FormatByteSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", bytes)
+}
+
+// isTerminal returns true if the file is an interactive character device
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat() // Inspect the file mode
+	if err != nil {          // Assume not a terminal if we can't tell
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,22 @@
+package downloader
+
+import "testing"
+
+func TestFormatByteSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 << 20, "5.0 MB"},
+		{3 << 30, "3.0 GB"},
+	}
+	for _, test := range tests {
+		if got := FormatByteSize(test.bytes); got != test.want {
+			t.Errorf("FormatByteSize(%d) = %q, want %q", test.bytes, got, test.want)
+		}
+	}
+}
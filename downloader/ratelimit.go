@@ -0,0 +1,78 @@
+package downloader // Per-host request rate limiting shared by all workers
+
+import (
+	"context"      // For cancelling a wait on the limiter
+	"math/rand/v2" // For the jitter delay
+	"net/url"      // For extracting the host from a URL
+	"sync"         // For guarding the limiter map
+	"time"         // For the jitter delay
+
+	"golang.org/x/time/rate" // Token-bucket rate limiter
+)
+
+// HostRateLimiter hands out one token-bucket limiter per host
+type HostRateLimiter struct {
+	mutex       sync.Mutex               // Guards limiters
+	limiters    map[string]*rate.Limiter // Host -> limiter
+	limit       rate.Limit               // Requests per second allowed for each host
+	minInterval time.Duration            // Smallest gap between consecutive requests to one host; 0 leaves it to limit
+}
+
+// NewHostRateLimiter creates a limiter allowing requestsPerSecond per host; zero or less means unlimited
+func NewHostRateLimiter(requestsPerSecond float64) *HostRateLimiter {
+	limit := rate.Limit(requestsPerSecond) // Convert to the limiter's unit
+	if requestsPerSecond <= 0 {            // No limit requested
+		limit = rate.Inf
+	}
+	return &HostRateLimiter{limiters: make(map[string]*rate.Limiter), limit: limit}
+}
+
+// SetMinInterval keeps consecutive requests to the same host at least interval apart, on top of the
+// requests-per-second limit; call it before the first Wait
+func (limiter *HostRateLimiter) SetMinInterval(interval time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.minInterval = interval
+}
+
+// Wait blocks until a request to rawURL's host is allowed or ctx is done
+func (limiter *HostRateLimiter) Wait(ctx context.Context, rawURL string) error {
+	parsedURL, err := url.Parse(rawURL) // Parse to find the host
+	if err != nil {                     // Let the request itself report the bad URL
+		return nil
+	}
+	return limiter.forHost(parsedURL.Host).Wait(ctx) // Wait for a token from this host's bucket
+}
+
+// forHost returns the limiter for host, creating it on first use
+func (limiter *HostRateLimiter) forHost(host string) *rate.Limiter {
+	limiter.mutex.Lock()         // Serialize access to the map
+	defer limiter.mutex.Unlock() // Release the lock when done
+
+	hostLimiter, found := limiter.limiters[host] // Look up this host's bucket
+	if !found {                                  // First request to this host
+		hostLimit := limiter.limit                                                  // Requests per second for this host
+		if limiter.minInterval > 0 && rate.Every(limiter.minInterval) < hostLimit { // The cooldown is the stricter bound
+			hostLimit = rate.Every(limiter.minInterval)
+		}
+		hostLimiter = rate.NewLimiter(hostLimit, 1) // Burst of one keeps requests evenly spaced
+		limiter.limiters[host] = hostLimiter
+	}
+	return hostLimiter
+}
+
+// waitJitter sleeps for a random time in [0, maxDelay) so downloads aren't evenly spaced,
+// returning early with ctx's error when it's cancelled
+func waitJitter(ctx context.Context, maxDelay time.Duration) error {
+	if maxDelay <= 0 { // Jitter disabled
+		return nil
+	}
+	timer := time.NewTimer(rand.N(maxDelay)) // Uniformly random pause
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
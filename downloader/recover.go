@@ -0,0 +1,22 @@
+package downloader // Keeping one link's panic from taking down the whole run
+
+import (
+	"errors"        // For the panic sentinel
+	"fmt"           // For wrapping the recovered value
+	"runtime/debug" // For logging where the panic happened
+)
+
+// ErrPanic is wrapped around the failure DownloadAll records for a link whose download or post-processing panicked
+var ErrPanic = errors.New("panic")
+
+// recoverPanic runs work, turning a panic in it into an error wrapping ErrPanic so the worker can record
+// the link as failed and move on to the next one
+func (downloader *Downloader) recoverPanic(link string, work func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			downloader.logger().Error("recovered from panic", "url", link, "panic", recovered, "stack", string(debug.Stack()))
+			err = fmt.Errorf("%w for %s: %v", ErrPanic, link, recovered)
+		}
+	}()
+	return work()
+}
@@ -0,0 +1,48 @@
+package downloader // Detection of response bodies that stop delivering data
+
+import (
+	"errors"      // For the stall sentinel
+	"fmt"         // For formatted error messages
+	"io"          // For reader plumbing
+	"sync/atomic" // For the flag the timer sets
+	"time"        // For the idle timer
+)
+
+// ErrStalled means a response body delivered no data for StallTimeout; the attempt is retried from the .part file
+var ErrStalled = errors.New("download stalled")
+
+// stallReader cancels its download attempt when the body goes StallTimeout without delivering a byte.
+// The clock restarts on every read that returns data, so a slow but steady transfer is never cut off.
+type stallReader struct {
+	body    io.Reader     // Response body being read
+	timeout time.Duration // Longest wait for the next byte
+	timer   *time.Timer   // Fires cancel once the body has been idle for timeout
+	stalled atomic.Bool   // Set when the timer fired, so the read error can say why
+}
+
+// newStallReader starts the idle clock for body, calling cancel if it runs out; call stop when done reading
+func newStallReader(body io.Reader, timeout time.Duration, cancel func()) *stallReader {
+	reader := &stallReader{body: body, timeout: timeout}
+	reader.timer = time.AfterFunc(timeout, func() {
+		reader.stalled.Store(true) // Before cancel, so the failed read sees it
+		cancel()
+	})
+	return reader
+}
+
+// Read reads from the body, restarting the idle clock when data arrives
+func (reader *stallReader) Read(buffer []byte) (int, error) {
+	read, err := reader.body.Read(buffer)
+	if read > 0 && !reader.stalled.Load() { // Data is flowing; give the next read the full allowance
+		reader.timer.Reset(reader.timeout)
+	}
+	if err != nil && err != io.EOF && reader.stalled.Load() { // The attempt was cancelled for idling, not by the run
+		return read, fmt.Errorf("%w: no data for %s", ErrStalled, reader.timeout)
+	}
+	return read, err
+}
+
+// stop disarms the idle clock
+func (reader *stallReader) stop() {
+	reader.timer.Stop()
+}
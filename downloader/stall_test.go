@@ -0,0 +1,76 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadPDFAbandonsStalledBody(t *testing.T) {
+	half := len(testPDF) / 2
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if requests.Add(1) > 1 { // The retry resumes where the stalled attempt stopped
+			writer.Header().Set("Content-Type", "application/pdf")
+			writer.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(testPDF)-1, len(testPDF)))
+			writer.WriteHeader(http.StatusPartialContent)
+			writer.Write(testPDF[half:])
+			return
+		}
+		writer.Header().Set("Content-Type", "application/pdf")
+		writer.Header().Set("Content-Length", fmt.Sprint(len(testPDF)))
+		writer.Write(testPDF[:half])
+		writer.(http.Flusher).Flush()
+		<-request.Context().Done() // Go quiet until the client gives up
+	}))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+	downloader := newTestDownloader(server)
+	downloader.StallTimeout = 50 * time.Millisecond
+
+	_, err := downloader.DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil)
+	if !errors.Is(err, ErrStalled) || !isRetryableDownloadError(context.Background(), err) {
+		t.Fatalf("err = %v, want a retryable ErrStalled", err)
+	}
+	if partial, err := os.ReadFile(filePath + ".part"); err != nil || !bytes.Equal(partial, testPDF[:half]) {
+		t.Fatalf(".part holds %d bytes (%v), want the %d received before the stall", len(partial), err, half)
+	}
+
+	if _, err := downloader.DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if saved, err := os.ReadFile(filePath); err != nil || !bytes.Equal(saved, testPDF) {
+		t.Errorf("saved %d bytes (%v) that differ from the %d-byte file", len(saved), err, len(testPDF))
+	}
+}
+
+func TestDownloadPDFAllowsSlowSteadyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/pdf")
+		for chunk := range slices.Chunk(testPDF, len(testPDF)/8) { // Each chunk arrives well within the stall timeout
+			writer.Write(chunk)
+			writer.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	filePath := filepath.Join(t.TempDir(), "a.pdf")
+	downloader := newTestDownloader(server)
+	downloader.StallTimeout = 100 * time.Millisecond // Shorter than the whole transfer
+
+	if _, err := downloader.DownloadPDF(context.Background(), server.URL+"/a.pdf", filePath, nil); err != nil {
+		t.Fatalf("DownloadPDF: %v", err)
+	}
+	if saved, err := os.ReadFile(filePath); err != nil || !bytes.Equal(saved, testPDF) {
+		t.Errorf("saved %d bytes (%v) that differ from the %d-byte file", len(saved), err, len(testPDF))
+	}
+}
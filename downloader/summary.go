@@ -0,0 +1,134 @@
+package downloader // End-of-run summary of what DownloadAll did
+
+import (
+	"crypto/tls" // For recognizing certificate failures
+	"errors"     // For classifying failures
+	"fmt"        // For naming HTTP status failures
+	"io"         // For recognizing truncated bodies
+	"log/slog"   // For structured logging
+	"net"        // For recognizing network errors
+	"time"       // For the elapsed time
+)
+
+// Summary counts the outcome of every link in a DownloadAll run
+type Summary struct {
+	LinksFound       int            `json:"links_found"`       // Links handed to DownloadAll
+	Downloaded       int            `json:"downloaded"`        // PDFs fetched and saved, including duplicates of existing content
+	AlreadyPresent   int            `json:"already_present"`   // Links whose file was already on disk or not modified
+	AlreadyProcessed int            `json:"already_processed"` // Links skipped because the link store already had them
+	Deferred         int            `json:"deferred"`          // Links left for a later run by the MaxNew limit
+	Failures         map[string]int `json:"failures"`          // Failed links by reason, e.g. "http 404", "soft 404", "network"
+	FailedURLs       []string       `json:"failed_urls"`       // Links that failed, in completion order
+	FailureDetails   []Failure      `json:"failure_details"`   // Why each link in FailedURLs failed, in the same order
+	NewFiles         []string       `json:"new_files"`         // Paths of the PDFs fetched this run
+	BytesWritten     int64          `json:"bytes_written"`     // Bytes of PDF data saved
+
+	MinBytesPerSecond float64 `json:"min_bytes_per_second"` // Slowest download's throughput
+	MaxBytesPerSecond float64 `json:"max_bytes_per_second"` // Fastest download's throughput
+	AvgBytesPerSecond float64 `json:"avg_bytes_per_second"` // Mean throughput over the downloads
+	throughputSamples int     // Downloads averaged into AvgBytesPerSecond
+
+	Elapsed time.Duration `json:"-"` // Wall time of the run; set by the caller
+}
+
+// Failure says why one link failed
+type Failure struct {
+	URL    string `json:"url"`    // Link that failed
+	Reason string `json:"reason"` // Broad cause, as counted in Failures
+	Error  string `json:"error"`  // Full error message
+}
+
+// Failed returns the number of links that failed for any reason
+func (summary *Summary) Failed() int {
+	total := 0                               // Sum over every reason
+	for _, count := range summary.Failures { // Add up each reason's count
+		total += count
+	}
+	return total
+}
+
+// addFailure counts one failed link under the reason err falls into
+func (summary *Summary) addFailure(link string, err error) {
+	if summary.Failures == nil { // First failure of the run
+		summary.Failures = make(map[string]int)
+	}
+	reason := failureReason(err)
+	summary.Failures[reason]++
+	summary.FailedURLs = append(summary.FailedURLs, link)
+	summary.FailureDetails = append(summary.FailureDetails, Failure{URL: link, Reason: reason, Error: err.Error()})
+}
+
+// addThroughput folds one download's bytes per second into the min, max and average
+func (summary *Summary) addThroughput(rate float64) {
+	if rate <= 0 { // Too quick to measure
+		return
+	}
+	if summary.throughputSamples == 0 || rate < summary.MinBytesPerSecond {
+		summary.MinBytesPerSecond = rate
+	}
+	summary.MaxBytesPerSecond = max(summary.MaxBytesPerSecond, rate)
+	summary.throughputSamples++
+	summary.AvgBytesPerSecond += (rate - summary.AvgBytesPerSecond) / float64(summary.throughputSamples) // Running mean
+}
+
+// Log writes the summary as one structured log line to the default logger
+func (summary *Summary) Log() {
+	summary.LogTo(slog.Default())
+}
+
+// LogTo writes the summary as one structured log line to logger
+func (summary *Summary) LogTo(logger *slog.Logger) {
+	logger.Info("run summary",
+		"links_found", summary.LinksFound,
+		"downloaded", summary.Downloaded,
+		"already_present", summary.AlreadyPresent,
+		"already_processed", summary.AlreadyProcessed,
+		"deferred", summary.Deferred,
+		"failed", summary.Failed(),
+		"failures", summary.Failures,
+		"bytes_written", summary.BytesWritten,
+		"min_bytes_per_second", int64(summary.MinBytesPerSecond),
+		"max_bytes_per_second", int64(summary.MaxBytesPerSecond),
+		"avg_bytes_per_second", int64(summary.AvgBytesPerSecond),
+		"elapsed", summary.Elapsed.Round(time.Millisecond),
+	)
+}
+
+// failureReason names the broad cause of a download error for the summary
+func failureReason(err error) string {
+	var statusErr *ErrBadStatus
+	var certErr *tls.CertificateVerificationError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr): // Server answered with an error status
+		return fmt.Sprintf("http %d", statusErr.Code)
+	case errors.Is(err, ErrSoft404): // 200 response carrying an HTML error page
+		return "soft 404"
+	case errors.Is(err, ErrWrongContentType): // Served as some other file type
+		return "wrong content type"
+	case errors.Is(err, ErrNotPDF): // Content isn't a PDF
+		return "not a pdf"
+	case errors.Is(err, ErrZeroBytes): // Nothing in the body
+		return "zero bytes"
+	case errors.Is(err, ErrMismatch): // Content differs from the expected hash
+		return "checksum mismatch"
+	case errors.Is(err, ErrTooLarge): // Bigger than MaxSize
+		return "too large"
+	case errors.Is(err, ErrTooSmall): // Smaller than MinSize
+		return "too small"
+	case errors.Is(err, ErrPanic): // A bug in the download path or the PostProcessor
+		return "panic"
+	case errors.Is(err, ErrFileTimeout): // Took longer than FileTimeout over all its attempts
+		return "timeout"
+	case errors.Is(err, ErrStalled): // The body stopped delivering data for StallTimeout
+		return "stalled"
+	case errors.Is(err, ErrPostProcess): // Rejected or failed by the PostProcessor
+		return "post-process"
+	case errors.As(err, &certErr): // Untrusted certificate or -pin-sha256 mismatch
+		return "certificate"
+	case errors.As(err, &netErr), errors.Is(err, io.ErrUnexpectedEOF): // Timeouts, resets, DNS, cut-off bodies
+		return "network"
+	default:
+		return "other"
+	}
+}
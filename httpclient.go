@@ -0,0 +1,130 @@
+package main // Shared HTTP client for every outbound request
+
+import (
+	"crypto/sha256"   // For hashing pinned public keys
+	"crypto/tls"      // For certificate verification settings
+	"crypto/x509"     // For the CA certificate pool
+	"encoding/base64" // For the -pin-sha256 encoding
+	"fmt"             // For wrapping errors
+	"net/http"        // For the client and transport
+	"os"              // For reading the CA file
+	"strings"         // For comparing host names
+	"time"            // For timeouts
+)
+
+// newHTTPClient builds the shared client: requests go through proxyURL (or the environment proxy), each
+// request is limited to timeout and must see response headers within headerTimeout, up to idlePerHost
+// keep-alive connections are kept open per host, every request carries userAgent and the extra headers, requests
+// to credentialHosts also carry the credentials headers, and HTTPS connections are verified with tlsConfig, or
+// Go's defaults when it is nil
+func newHTTPClient(proxyURL string, timeout, headerTimeout time.Duration, idlePerHost int, userAgent string, headers, credentials map[string]string, credentialHosts []string, tlsConfig *tls.Config) (*http.Client, error) {
+	transport, err := newHTTPTransport(proxyURL) // Proxy-aware transport with Go's defaults
+	if err != nil {
+		return nil, err
+	}
+	transport.MaxIdleConns = 100                    // Overall cap on idle connections
+	transport.MaxIdleConnsPerHost = idlePerHost     // One idle connection per worker avoids reconnecting to the same host
+	transport.IdleConnTimeout = 90 * time.Second    // Close connections that sit unused between batches
+	transport.ResponseHeaderTimeout = headerTimeout // Give up on servers that accept the connection but never answer
+	if tlsConfig != nil {                           // Custom CA or verification turned off
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	withHeaders := &headerTransport{next: transport, userAgent: userAgent, headers: headers, credentials: credentials, credentialHosts: credentialHosts} // Identify every request the same way
+	return &http.Client{Timeout: timeout, Transport: withHeaders}, nil
+}
+
+// newTLSConfig returns the TLS settings for -ca-cert, -insecure-skip-verify and -pin-sha256: the system roots
+// plus the certificates in the PEM file at caCertPath, no verification at all when insecure is set, and, when
+// pin is set, a check that pinHost's leaf certificate has that public key. It returns nil when none is given,
+// so Go's defaults apply.
+func newTLSConfig(caCertPath string, insecure bool, pinHost, pin string) (*tls.Config, error) {
+	if caCertPath == "" && !insecure && pin == "" { // Full verification against the system roots
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure} // Skipping verification only when explicitly asked for
+	if pin != "" {                                         // Runs on every handshake, resumed or not, even with insecure set
+		tlsConfig.VerifyConnection = verifyPinnedKey(pinHost, pin)
+	}
+	if caCertPath == "" {
+		return tlsConfig, nil
+	}
+
+	pemBytes, err := os.ReadFile(caCertPath) // Read the extra CA bundle
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate %s: %w", caCertPath, err)
+	}
+	roots, err := x509.SystemCertPool() // Keep trusting the usual public CAs
+	if err != nil {                     // No system store on this platform; trust only the given CAs
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("no PEM certificates found in CA certificate %s", caCertPath)
+	}
+	tlsConfig.RootCAs = roots
+	return tlsConfig, nil
+}
+
+// publicKeyPin returns the base64 SHA-256 hash of the certificate's public key, the form -pin-sha256 takes
+func publicKeyPin(certificate *x509.Certificate) string {
+	keyHash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(keyHash[:])
+}
+
+// verifyPinnedKey returns a VerifyConnection check that rejects connections to host whose leaf certificate
+// doesn't have the public key pin hashes to; connections to other hosts are left to normal verification
+func verifyPinnedKey(host, pin string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if !strings.EqualFold(strings.TrimSuffix(state.ServerName, "."), host) { // Only the base host is pinned
+			return nil
+		}
+		if len(state.PeerCertificates) == 0 { // Nothing to compare the pin with
+			return &tls.CertificateVerificationError{Err: fmt.Errorf("%s presented no certificate to check against the pinned key", host)}
+		}
+		if got := publicKeyPin(state.PeerCertificates[0]); got != pin {
+			return &tls.CertificateVerificationError{
+				UnverifiedCertificates: state.PeerCertificates,
+				Err:                    fmt.Errorf("certificate pin mismatch for %s: public key hash is %s, -pin-sha256 expects %s", host, got, pin),
+			}
+		}
+		return nil
+	}
+}
+
+// headerTransport sets the configured User-Agent and extra headers on every outgoing request, and the
+// credentials only on requests to the hosts they belong to
+type headerTransport struct {
+	next            http.RoundTripper // Transport that sends the request
+	userAgent       string            // User-Agent replacing Go's default; empty keeps it
+	headers         map[string]string // Extra headers sent with every request
+	credentials     map[string]string // Headers such as Authorization sent only to credentialHosts
+	credentialHosts []string          // host[:port] values the credentials may be sent to
+}
+
+// RoundTrip adds the headers to a copy of the request and sends it; it runs for every redirect hop, so a
+// redirect to a CDN or another site goes out without the credentials
+func (transport *headerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context()) // RoundTrippers must not modify the caller's request
+	if transport.userAgent != "" {             // Replace Go's default User-Agent
+		request.Header.Set("User-Agent", transport.userAgent)
+	}
+	for name, value := range transport.headers { // Add any extra configured headers
+		request.Header.Set(name, value)
+	}
+	if credentialHost(request.URL.Host, transport.credentialHosts) { // Only the site being scraped gets the credentials
+		for name, value := range transport.credentials {
+			request.Header.Set(name, value)
+		}
+	}
+	return transport.next.RoundTrip(request)
+}
+
+// credentialHost reports whether host, as in a URL's Host field, is one of hosts
+func credentialHost(host string, hosts []string) bool {
+	for _, allowed := range hosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeaderTransportSendsConfiguredHeaders(t *testing.T) {
+	var got http.Header // Headers the site saw
+	site := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		got = request.Header.Clone()
+	}))
+	defer site.Close()
+
+	headers := map[string]string{"X-Api-Key": "secret", "Accept-Language": "en-US"}
+	client, err := newHTTPClient("", time.Minute, time.Minute, 1, "sds-bot/1.0", headers, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(site.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got.Get("User-Agent") != "sds-bot/1.0" {
+		t.Errorf("User-Agent = %q, want sds-bot/1.0", got.Get("User-Agent"))
+	}
+	for name, value := range headers {
+		if got.Get(name) != value {
+			t.Errorf("%s = %q, want %q", name, got.Get(name), value)
+		}
+	}
+}
+
+func TestHeaderTransportKeepsCredentialsOnTheirHost(t *testing.T) {
+	var cdnAuthorization string // Authorization the other host saw, if any
+	cdn := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		cdnAuthorization = request.Header.Get("Authorization")
+	}))
+	defer cdn.Close()
+	var siteAuthorization string // Authorization the scraped site saw
+	site := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		siteAuthorization = request.Header.Get("Authorization")
+		http.Redirect(writer, request, cdn.URL+"/a.pdf", http.StatusFound)
+	}))
+	defer site.Close()
+
+	credentials := map[string]string{"Authorization": "Bearer token"}
+	siteHost := strings.TrimPrefix(site.URL, "http://")
+	client, err := newHTTPClient("", time.Minute, time.Minute, 1, "", nil, credentials, []string{siteHost}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(site.URL + "/a.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if siteAuthorization != "Bearer token" {
+		t.Errorf("site got Authorization %q, want the bearer token", siteAuthorization)
+	}
+	if cdnAuthorization != "" {
+		t.Errorf("redirect target got Authorization %q, want none", cdnAuthorization)
+	}
+}
@@ -0,0 +1,68 @@
+package main // Incremental runs that only download links new since the previous snapshot
+
+import (
+	"errors"   // For detecting a missing snapshot
+	"io/fs"    // For the not-exist sentinel error
+	"log/slog" // For structured logging
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // Run summary
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"    // Link list files
+)
+
+// addedLinks returns the links that aren't in the snapshot at snapshotPath, in their original order, and
+// logs the snapshot's links that are gone; with no snapshot yet, every link is new
+func addedLinks(snapshotPath string, links []string) ([]string, error) {
+	previousLinks, _, err := storage.LoadLinkList(snapshotPath)
+	if errors.Is(err, fs.ErrNotExist) { // First incremental run
+		slog.Info("no previous link snapshot, treating every link as new", "file", snapshotPath)
+		return links, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[string]bool, len(previousLinks)) // Links in the snapshot
+	for _, link := range previousLinks {
+		previous[link] = true
+	}
+	current := make(map[string]bool, len(links)) // Links on the page now
+	var added []string
+	for _, link := range links {
+		current[link] = true
+		if !previous[link] {
+			added = append(added, link)
+		}
+	}
+	var removed []string
+	for _, link := range previousLinks {
+		if !current[link] {
+			removed = append(removed, link)
+		}
+	}
+
+	for _, link := range removed { // Withdrawn sheets are reported, never deleted here
+		slog.Info("link removed since the previous snapshot", "url", link)
+	}
+	slog.Info("compared links with the previous snapshot", "file", snapshotPath, "added", len(added), "removed", len(removed), "unchanged", len(links)-len(added))
+	return added, nil
+}
+
+// saveLinkSnapshot records links as the snapshot for the next incremental run, leaving out the ones that
+// failed so they count as new again; a run cut short keeps the old snapshot so nothing is skipped
+func saveLinkSnapshot(snapshotPath string, links []string, labels map[string]storage.LinkLabel, summary downloader.Summary, interrupted bool) error {
+	if interrupted || summary.Deferred > 0 { // Some additions were never tried
+		slog.Warn("not every new link was downloaded; keeping the previous link snapshot", "file", snapshotPath)
+		return nil
+	}
+	failed := make(map[string]bool, len(summary.FailedURLs))
+	for _, link := range summary.FailedURLs {
+		failed[link] = true
+	}
+	var snapshot []string
+	for _, link := range links {
+		if !failed[link] {
+			snapshot = append(snapshot, link)
+		}
+	}
+	return storage.SaveLinkList(snapshotPath, snapshot, labels)
+}
@@ -0,0 +1,60 @@
+package main // Structured logger construction
+
+import (
+	"fmt"      // For formatted error messages
+	"io"       // For the log destination
+	"log/slog" // For structured logging
+	"strings"  // For case-insensitive option parsing
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // For the skip log level
+)
+
+// parseLogLevel converts a -log-level value to a slog level
+func parseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("log level must be debug, info, warn, or error, got %q", name)
+}
+
+// logLevel resolves the minimum level: -q shows only warnings and errors, -v adds skipped links and
+// files, -vv adds debug details, and without any of them -log-level decides
+func logLevel(levelName string, verbosity int) (slog.Level, error) {
+	switch {
+	case verbosity < 0:
+		return slog.LevelWarn, nil
+	case verbosity == 1:
+		return storage.LevelSkip, nil
+	case verbosity > 1:
+		return slog.LevelDebug, nil
+	}
+	return parseLogLevel(levelName)
+}
+
+// newLogger builds a text or JSON slog logger writing to output at the given level
+func newLogger(output io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: nameSkipLevel} // Handler options shared by both formats
+
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(output, options)), nil // key=value lines for terminals
+	case "json":
+		return slog.New(slog.NewJSONHandler(output, options)), nil // One JSON object per line for log aggregators
+	}
+	return nil, fmt.Errorf("log format must be text or json, got %q", format)
+}
+
+// nameSkipLevel prints storage.LevelSkip as SKIP instead of slog's DEBUG+2
+func nameSkipLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 && attr.Value.Any() == storage.LevelSkip {
+		attr.Value = slog.StringValue("SKIP")
+	}
+	return attr
+}
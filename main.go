@@ -1,292 +1,477 @@
 package main // Declare the main package for the executable program
 
 import (
-	"bytes"         // For buffering binary data
 	"context"       // For managing deadlines, cancellation signals, etc.
+	"encoding/json" // For the list-only output
+	"errors"        // For inspecting wrapped errors
+	"flag"          // For detecting help requests
 	"fmt"           // For formatted I/O
-	"io"            // For I/O primitives (Read, Write, etc.)
-	"log"           // For logging messages
-	"net/http"      // For HTTP client functionality
-	"net/url"       // For parsing and building URLs
+	"log/slog"      // For structured logging
 	"os"            // For file and system operations
-	"path"          // For manipulating slash-separated paths
+	"os/signal"     // For handling interrupt signals
 	"path/filepath" // For manipulating file system paths
-	"regexp"        // For regular expressions
-	"strings"       // For string manipulation
+	"regexp"        // For the include and exclude filters
+	"syscall"       // For signal constants
+	"text/template" // For the filename template
 	"time"          // For working with time durations and timestamps
 
-	"github.com/PuerkitoBio/goquery" // HTML document parser based on jQuery-like syntax
-	"github.com/chromedp/chromedp"   // Headless Chrome/Chromium browser automation
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // PDF downloads
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/scraper"    // Page scraping and link discovery
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"    // Link store, manifest, and file helpers
 )
 
-var localPDFLocation = "pdf_links.txt" // File path for storing downloaded PDF links
-
 func main() {
-	htmlFileLocation := "duragloss.html" // Path to locally stored HTML content
+	runStart := time.Now() // Start of the run, for the summary's elapsed time
 
-	if !fileExists(htmlFileLocation) { // If HTML file doesn't exist locally
-		urlToScrape := "https://www.duragloss.com/sds-sheets/" // Target URL to scrape PDF links from
-		data := scrapePageHTMLWithChrome(urlToScrape)          // Render page HTML using headless Chrome
-		appendAndWriteToFile("duragloss.html", string(data))   // Save the scraped HTML to file
+	config, err := parseConfig(os.Args[0], os.Args[1:]) // Build settings from defaults, config file, and flags
+	if errors.Is(err, flag.ErrHelp) {                   // -h or -help was requested
+		os.Exit(0) // Usage has been printed; nothing else to do
+	}
+	if err != nil { // Bad input has already been reported with usage
+		os.Exit(2) // Exit with the conventional usage-error status
 	}
 
-	outputDir := "PDFs"              // Directory name to save downloaded PDFs
-	if !directoryExists(outputDir) { // If output directory doesn't exist
-		createDirectory(outputDir, 0755) // Create output directory with appropriate permissions
+	level, err := logLevel(config.LogLevel, config.Verbosity) // -q, -v and -vv override -log-level
+	var logger *slog.Logger                                   // Destination for every log call
+	if err == nil {
+		logger, err = newLogger(os.Stderr, level, config.LogFormat) // Build the structured logger
 	}
+	if err != nil { // Options were validated, so this is unexpected
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger) // Route every log call through the structured logger
 
-	if fileExists(htmlFileLocation) { // Proceed if HTML file exists
-		htmlContent := readAFileAsString(htmlFileLocation) // Read the content of the HTML file
-		pdfLinks := extractPDFLinks(htmlContent)           // Extract PDF links from HTML
-		pdfLinks = removeDuplicatesFromSlice(pdfLinks)     // Remove duplicate links
+	tlsConfig, err := newTLSConfig(config.CACert, config.InsecureSkipVerify, config.pinnedHost(), config.PinSHA256) // Extra CA, pinned key, or no verification, for self-signed SDS hosts
+	if err != nil {                                                                                                 // The CA file was validated, so this is unexpected
+		slog.Error("failed to configure TLS", "error", err)
+		os.Exit(2)
+	}
+	if config.InsecureSkipVerify { // Make the weakened security impossible to miss in the log
+		slog.Warn("TLS certificate verification is DISABLED; page fetches and downloads can be intercepted or tampered with")
+	}
+	if config.CACert != "" && !config.NoChrome { // Chrome only trusts its own certificate store
+		slog.Warn("Chrome doesn't use -ca-cert; add the CA to Chrome's certificate store or use -no-chrome", "ca_cert", config.CACert)
+	}
+	if config.PinSHA256 != "" && !config.NoChrome { // Chrome verifies the pages it renders itself
+		slog.Warn("Chrome doesn't check -pin-sha256; page renders aren't pinned, use -no-chrome to pin them too", "host", config.pinnedHost())
+	}
+	if config.TimeoutPerFile.Duration > 0 && config.DownloadTimeout.Duration > 0 && config.TimeoutPerFile.Duration < config.DownloadTimeout.Duration { // The per-file limit cuts attempts short
+		slog.Warn("-timeout-per-file is shorter than -download-timeout, so no attempt can use its whole deadline", "timeout_per_file", config.TimeoutPerFile.Duration, "download_timeout", config.DownloadTimeout.Duration)
+	}
 
-		readLocalFile := readAFileAsString(localPDFLocation) // Read list of previously processed PDF links
+	httpClient, err := newHTTPClient(config.Proxy, config.Timeout.Duration, config.HeaderTimeout.Duration, max(config.Workers, 2), config.UserAgent, config.requestHeaders(), config.credentialHeaders(), config.credentialHosts(), tlsConfig) // Shared client through the configured or environment proxy
+	if err != nil {                                                                                                                                                                                                                            // The proxy URL was validated, so this is unexpected
+		slog.Error("failed to configure proxy", "error", err)
+		os.Exit(2)
+	}
 
-		for _, link := range pdfLinks { // Iterate over each PDF link
-			domain := extractDomainURL(link) // Extract domain to determine if it's a full or relative URL
-			if domain == "" {                // If no domain found (relative link)
-				link = "https://www.duragloss.com" + link // Prepend base URL to make it absolute
-			}
-			downloadPDF(link, outputDir) // Attempt to download the PDF file
+	nameTemplate, err := storage.ParseNameTemplate(config.nameTemplate()) // Custom filename scheme or mirrored layout, if any
+	if err != nil {                                                       // The template was validated, so this is unexpected
+		slog.Error("failed to parse name template", "error", err)
+		os.Exit(2)
+	}
 
-			if strings.Contains(readLocalFile, link) { // Skip already processed links
-				log.Printf("Link already processed, skipping: %s", link) // Log skip info
-				continue                                                 // Move to next link
-			}
+	pdfHashIndex, err := storage.LoadContentHashIndex(config.HashIndex) // Load hashes of previously saved PDFs
+	if err != nil {                                                     // A corrupt index would defeat deduplication
+		slog.Error("failed to load hash index", "file", config.HashIndex, "error", err)
+		os.Exit(1)
+	}
 
-			if isUrlValid(link) { // Check if the final URL is a valid URL
-				appendAndWriteToFile(localPDFLocation, link) // Append new link to tracking file
-			}
+	var partition string                     // Subdirectory for this run's new files
+	if config.Partition == partitionByDate { // Group new files by the day the run started
+		partition = runStart.Format("2006-01-02")
+	}
+
+	var expectedHashes map[string]string // Known-good digests, keyed like the download links
+	if config.Verify != "" {             // Verification requested
+		loaded, err := storage.LoadExpectedHashes(config.Verify)
+		if err != nil { // Verifying against nothing would be misleading
+			slog.Error("failed to load expected hashes", "file", config.Verify, "error", err)
+			os.Exit(1)
+		}
+		expectedHashes = make(map[string]string, len(loaded))
+		for link, digest := range loaded { // Canonicalize URLs the same way discovered links are
+			expectedHashes[canonicalLink(link, config.BaseURL)] = digest
 		}
-	} else {
-		log.Println("HTML file does not exist.") // Log message if HTML file is missing
 	}
-}
 
-// extractDomainURL extracts and returns only the domain name from a given URL
-func extractDomainURL(inputUrl string) string {
-	parsedUrl, parseError := url.Parse(inputUrl) // Attempt to parse the input URL
-	if parseError != nil {                       // Handle any parse error
-		log.Println("Error parsing URL:", parseError) // Log error
-		return ""                                     // Return empty string if parsing fails
+	pageScraper := scraper.New(httpClient, config.UserAgent, config.IgnoreRobots) // Fetches pages, sitemaps, and robots.txt
+	pageScraper.Extensions = config.Extensions                                    // Document types to look for
+	pageScraper.ContentTypes = config.ContentTypes
+
+	rateLimiter := downloader.NewHostRateLimiter(config.RequestsPerSecond) // Per-host pacing for the downloads
+	rateLimiter.SetMinInterval(config.DelayBetweenHosts.Duration)          // Cooldown between requests to one host, if any
+
+	downloadClient := *httpClient            // Same transport and connection pool, but without the total request timeout
+	downloadClient.Timeout = 0               // Downloads are bounded by DownloadTimeout instead
+	pdfDownloader := &downloader.Downloader{ // Shared state for the download workers
+		HTTPClient:        &downloadClient,
+		RateLimiter:       rateLimiter,
+		Jitter:            config.Jitter.Duration,
+		Shuffle:           config.Shuffle,
+		InterleaveHosts:   config.DelayBetweenHosts.Duration > 0,
+		HashIndex:         pdfHashIndex,
+		LinkFile:          config.LinkFile,
+		Database:          config.Database,
+		Force:             config.Force,
+		OverwriteIfLarger: config.OverwriteIfLarger,
+		MaxSize:           int64(config.MaxSize),
+		MinSize:           int64(config.MinSize),
+		ContentTypes:      config.ContentTypes,
+		Accept:            config.Accept,
+		MaxNew:            config.MaxNew,
+
+		DownloadTimeout: config.DownloadTimeout.Duration,
+		StallTimeout:    config.StallTimeout.Duration,
+		FileTimeout:     config.TimeoutPerFile.Duration,
+		SlowThreshold:   config.SlowDownload.Duration,
+		RetryFailed:     config.RetryFailed,
+		RetryDelay:      config.RetryDelay.Duration,
+		FailFast:        config.FailFast,
+		NameTemplate:    nameTemplate,
+		Partition:       partition,
+
+		ExpectedHashes:     expectedHashes,
+		DeleteOnMismatch:   config.DeleteOnMismatch,
+		HardlinkDuplicates: config.HardlinkDuplicates,
 	}
-	domainName := parsedUrl.Hostname() // Extract and return hostname (domain)
-	return domainName                  // Return domain name
-}
 
-// extractPDFLinks parses HTML content and returns all hyperlinks that end in .pdf
-func extractPDFLinks(html string) []string {
-	var pdfLinks []string // Slice to store PDF links
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM) // Cancel the download path on Ctrl-C or SIGTERM
+	defer stop()                                                                           // Release signal resources on exit
+	go func() {
+		<-ctx.Done() // First signal: let the download path wind down and flush
+		stop()       // Restore default handling so a second Ctrl-C exits immediately
+	}()
 
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
-	if err != nil {                                                    // Handle parsing error
-		log.Println("Error parsing HTML:", err) // Log error
-		return nil                              // Return nil on failure
+	if config.SelfTest { // Check the environment instead of running
+		if !runSelfTest(ctx, config, httpClient) {
+			os.Exit(1)
+		}
+		return
 	}
 
-	doc.Find("a").Each(func(i int, s *goquery.Selection) { // Iterate over all <a> tags
-		if href, exists := s.Attr("href"); exists && strings.HasSuffix(strings.ToLower(href), ".pdf") { // Check if href ends with .pdf
-			pdfLinks = append(pdfLinks, href) // Add the PDF link to the slice
+	var metrics *runMetrics       // Prometheus collectors, when -metrics-addr is set
+	if config.MetricsAddr != "" { // Expose progress to a scraper for the length of the run
+		metrics = newRunMetrics()
+		metricsServer, err := serveMetrics(config.MetricsAddr)
+		if err != nil {
+			slog.Error("failed to start metrics server", "error", err)
+			os.Exit(1)
 		}
-	})
-
-	return pdfLinks // Return the slice of PDF links
-}
-
-// isUrlValid returns true if the given URL is valid
-func isUrlValid(uri string) bool {
-	_, err := url.ParseRequestURI(uri) // Attempt to parse URL string
-	return err == nil                  // Return true if no error, else false
-}
+		defer metricsServer.Close()
+		pdfDownloader.OnResult = metrics.observeDownload
+	}
 
-// scrapePageHTMLWithChrome uses headless Chrome to fetch fully rendered HTML from a URL
-func scrapePageHTMLWithChrome(pageURL string) string {
-	fmt.Println("Scraping:", pageURL) // Log scraping action
+	outputDir := config.OutputDir                                            // Directory name to save downloaded PDFs
+	writesOutput := !config.DryRun && !config.ListOnly && !config.ScrapeOnly // Only runs that download touch the output
+	if writesOutput && !storage.DirectoryExists(outputDir) {                 // If output directory doesn't exist
+		if err := storage.CreateDirectory(outputDir, 0755); err != nil { // Create output directory with appropriate permissions
+			slog.Error("failed to create output directory", "error", err)
+			os.Exit(1)
+		}
+	}
+	if writesOutput { // Fail fast instead of failing every download
+		trackingFile := config.LinkFile // Where processed links are recorded
+		if config.Database != "" {
+			trackingFile = config.Database
+		}
+		for _, directory := range []string{outputDir, filepath.Dir(trackingFile)} {
+			if err := storage.CheckWritable(directory); err != nil {
+				slog.Error("cannot write output", "error", err)
+				os.Exit(1)
+			}
+		}
+	}
 
-	options := append(chromedp.DefaultExecAllocatorOptions[:], // Create list of Chrome options
-		chromedp.Flag("headless", true),               // Run Chrome in headless mode
-		chromedp.Flag("disable-gpu", true),            // Disable GPU for stability
-		chromedp.WindowSize(1920, 1080),               // Set viewport size
-		chromedp.Flag("no-sandbox", true),             // Disable sandbox (needed in some envs)
-		chromedp.Flag("disable-setuid-sandbox", true), // Disable setuid sandbox
-	)
+	if config.Pipeline { // Download while the seeds are still being scraped
+		summary := runPipeline(ctx, pageScraper, pdfDownloader, config)
+		summary.Elapsed = time.Since(runStart) // Include scraping time, not just downloads
+		if metrics != nil {                    // Only known once discovery is done
+			metrics.linksDiscovered.Set(float64(summary.LinksFound))
+		}
+		finishRun(ctx, config, logger, summary)
+		return
+	}
 
-	allocatorCtx, cancelAllocator := chromedp.NewExecAllocator(context.Background(), options...) // Create Chrome allocator context
+	var pdfLinks []string      // Links from the URL list, or from the seeds and sitemap
+	if config.URLsFile != "" { // Download a known set of URLs without scraping
+		pdfLinks, err = storage.LoadURLList(config.URLsFile)
+		if err != nil {
+			slog.Error("failed to load URL list", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("read URL list", "file", config.URLsFile, "links", len(pdfLinks))
+	} else if config.HTMLFile != "" { // Extract from a saved page instead of scraping
+		pdfLinks, pdfDownloader.Labels, err = linksFromHTMLFile(config.HTMLFile, pageScraper, config)
+		if err != nil {
+			slog.Error("failed to read HTML file", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("read HTML file", "file", config.HTMLFile, "links", len(pdfLinks))
+	} else if config.DownloadOnly { // Download what an earlier -scrape-only run found
+		pdfLinks, pdfDownloader.Labels, err = storage.LoadLinkList(config.LinkListFile)
+		if err != nil {
+			slog.Error("failed to load link list", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("read link list", "file", config.LinkListFile, "links", len(pdfLinks))
+	} else {
+		pdfLinks, pdfDownloader.Labels, err = discoverLinks(ctx, pageScraper, config, nil) // Labels end up in the manifest
+		if err != nil {
+			slog.Error("failed to discover links", "error", err)
+			os.Exit(1)
+		}
+	}
+	for linkIndex, link := range pdfLinks { // Canonicalize links so equivalent spellings dedupe together
+		pdfLinks[linkIndex] = canonicalLink(link, config.BaseURL)
+	}
+	pdfLinks = removeDuplicates(pdfLinks) // Remove duplicate links
 
-	ctxTimeout, cancelTimeout := context.WithTimeout(allocatorCtx, 5*time.Minute) // Set timeout for Chrome session
+	if config.URLsFile == "" && !config.DownloadOnly && !config.ListOnly && config.LinkListFile != "" { // Keep what this scrape found for -download-only
+		if err := storage.SaveLinkList(config.LinkListFile, pdfLinks, pdfDownloader.Labels); err != nil {
+			slog.Error("failed to save link list", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("saved link list", "file", config.LinkListFile, "links", len(pdfLinks))
+	}
+	if config.ScrapeOnly { // Downloading is left to a later -download-only run
+		return
+	}
+	currentLinks := pdfLinks  // Every link found, for the next snapshot
+	if config.OnlyNewInHTML { // Narrow the run to what appeared since the last one
+		pdfLinks, err = addedLinks(config.PrevLinksFile, pdfLinks)
+		if err != nil {
+			slog.Error("failed to compare with the previous links", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	browserCtx, cancelBrowser := chromedp.NewContext(ctxTimeout) // Create browser tab context
+	includePattern, excludePattern, _ := config.linkPatterns() // Validated with the rest of the config
+	allowedHosts := config.allowedHosts()                      // Hosts PDFs may come from
+	var absoluteLinks []string                                 // Slice to hold absolute PDF URLs
+	for _, link := range pdfLinks {                            // Iterate over each PDF link
+		if linkWanted(ctx, pageScraper, link, includePattern, excludePattern, allowedHosts) {
+			absoluteLinks = append(absoluteLinks, link) // Queue the absolute link for download
+		}
+	}
 
-	defer func() { // Ensure all contexts are cleaned up
-		cancelBrowser()
-		cancelTimeout()
-		cancelAllocator()
-	}()
+	if newerThan, _ := config.newerThanDate(); !newerThan.IsZero() { // Only keep sheets revised recently
+		absoluteLinks = filterLinksNewerThan(ctx, absoluteLinks, pdfDownloader.Labels, newerThan)
+	}
 
-	var pageHTML string // Variable to store final HTML
+	if since, _ := config.sinceDate(); !since.IsZero() { // Only keep links first seen recently
+		absoluteLinks, err = filterLinksSince(absoluteLinks, config.Database, config.LinkFile, since, config.SinceStrict)
+		if err != nil { // Without the store we can't tell which links are new
+			slog.Error("failed to apply since filter", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	err := chromedp.Run(browserCtx, // Run ChromeDP tasks
-		chromedp.Navigate(pageURL),            // Navigate to page
-		chromedp.OuterHTML("html", &pageHTML), // Extract full page HTML
-	)
-	if err != nil { // If scraping fails
-		log.Printf("Failed to scrape %s: %v", pageURL, err) // Log failure
-		return ""                                           // Return empty string
+	if metrics != nil { // What this run is about to download
+		metrics.linksDiscovered.Set(float64(len(absoluteLinks)))
 	}
-	return pageHTML // Return the scraped HTML
-}
 
-// getDataFromURL performs a GET request and returns the response body as bytes
-func getDataFromURL(uri string) []byte {
-	response, err := http.Get(uri) // Perform HTTP GET request
-	if err != nil {                // Handle request error
-		log.Println(err)
+	if config.Prune || config.PruneDelete { // Clean up instead of downloading
+		if err := pruneStaleFiles(outputDir, pdfLinks, config.PruneDelete); err != nil {
+			slog.Error("failed to prune", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
-	body, err := io.ReadAll(response.Body) // Read the response body
-	if err != nil {                        // Handle read error
-		log.Println(err)
+	if config.ListOnly { // Only emit the links for other tools
+		if err := printLinksJSON(absoluteLinks); err != nil {
+			slog.Error("failed to write link list", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
-	err = response.Body.Close() // Close the response body
-	if err != nil {             // Handle close error
-		log.Println(err)
+	if config.DryRun { // Only report what would be downloaded
+		printDryRun(absoluteLinks, outputDir, nameTemplate, partition)
+		return
 	}
-	return body // Return response data
-}
-
-// urlToSafeFilename sanitizes a URL into a filesystem-safe filename
-func urlToSafeFilename(rawURL string) string {
-	parsedURL, err := url.Parse(rawURL) // Parse the raw URL
-	if err != nil {                     // Handle parse error
-		return "" // Return empty string if parse fails
-	}
-	base := path.Base(parsedURL.Path)       // Get the file name portion of the path
-	decoded, err := url.QueryUnescape(base) // Decode URL-encoded string
-	if err != nil {                         // Fallback if decoding fails
-		decoded = base
-	}
-	decoded = strings.ToLower(decoded)        // Convert to lowercase
-	re := regexp.MustCompile(`[^a-z0-9._-]+`) // Regex to match invalid filename characters
-	safe := re.ReplaceAllString(decoded, "_") // Replace invalid characters with underscore
-	return safe                               // Return sanitized filename
-}
 
-// downloadPDF downloads a PDF file from the given URL and saves it to disk
-func downloadPDF(finalURL, outputDir string) {
-	filename := strings.ToLower(urlToSafeFilename(finalURL)) // Generate safe filename from URL
-	filePath := filepath.Join(outputDir, filename)           // Full path to save the PDF
+	summary := pdfDownloader.DownloadAll(ctx, absoluteLinks, outputDir, config.Workers, config.Attempts, config.Quiet || config.Verbosity < 0) // Download all PDFs using the worker pool
+	summary.Elapsed = time.Since(runStart)                                                                                                     // Include scraping time, not just downloads
 
-	if fileExists(filePath) { // Skip download if file already exists
-		log.Printf("file already exists, skipping: %s", filePath) // Log skip message
-		return
+	if config.OnlyNewInHTML { // The next incremental run starts from here
+		if err := saveLinkSnapshot(config.PrevLinksFile, currentLinks, pdfDownloader.Labels, summary, ctx.Err() != nil || (config.FailFast && summary.Failed() > 0)); err != nil { // Fail fast leaves links unscheduled
+			slog.Error("failed to save link snapshot", "error", err)
+		}
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second} // Create HTTP client with timeout
-	resp, err := client.Get(finalURL)                 // Send GET request to download PDF
-	if err != nil {                                   // Handle GET error
-		log.Printf("failed to download %s: %v", finalURL, err)
-		return
-	}
-	defer resp.Body.Close() // Ensure response body is closed
+	finishRun(ctx, config, logger, summary)
+}
 
-	if resp.StatusCode != http.StatusOK { // Check for 200 OK status
-		log.Printf("download failed for %s: %s", finalURL, resp.Status) // Log HTTP error
-		return
+// finishRun reports a finished download run: it logs the summary, validates, indexes, reports, and
+// notifies as configured, then exits 1 when too many downloads failed
+func finishRun(ctx context.Context, config Config, logger *slog.Logger, summary downloader.Summary) {
+	summaryLogger := logger   // Where the run summary goes
+	if config.Verbosity < 0 { // -q still reports the outcome
+		summaryLogger, _ = newLogger(os.Stderr, slog.LevelInfo, config.LogFormat)
 	}
+	summary.LogTo(summaryLogger) // Report what the run accomplished
 
-	contentType := resp.Header.Get("Content-Type")         // Get content type header
-	if !strings.Contains(contentType, "application/pdf") { // Ensure content is PDF
-		log.Printf("invalid content type for %s: %s (expected application/pdf)", finalURL, contentType)
-		return
+	if config.Validate || config.ValidateAll { // Catch truncated files that still start with %PDF-
+		filesToCheck := summary.NewFiles // Just this run's downloads
+		var err error
+		if config.ValidateAll {
+			filesToCheck, err = savedFiles(config.OutputDir)
+		}
+		if err == nil {
+			_, err = validatePDFs(filesToCheck, config.ValidateReport)
+		}
+		if err != nil {
+			slog.Error("failed to validate PDFs", "error", err)
+		}
 	}
 
-	var buf bytes.Buffer                     // Create buffer for file content
-	written, err := io.Copy(&buf, resp.Body) // Read response body into buffer
-	if err != nil {                          // Handle copy error
-		log.Printf("failed to read PDF data from %s: %v", finalURL, err)
-		return
+	if config.MirrorIndex { // Regenerated every run so it matches the manifest
+		if err := writeMirrorIndex(config.OutputDir); err != nil {
+			slog.Error("failed to write mirror index", "error", err)
+		}
 	}
 
-	if written == 0 { // If no bytes were written, skip file creation
-		log.Printf("downloaded 0 bytes for %s; not creating file", finalURL)
-		return
+	if config.Report != "" { // Export the summary, even after an interrupt
+		if err := writeReport(config.reportPath(), config.Report, summary); err != nil {
+			slog.Error("failed to write report", "error", err)
+		}
 	}
 
-	out, err := os.Create(filePath) // Create output file
-	if err != nil {                 // Handle file creation error
-		log.Printf("failed to create file for %s: %v", finalURL, err)
-		return
+	if config.NotifyURL != "" { // Tell the webhook how the run went, even after an interrupt
+		notifyClient, err := newHTTPClient(config.Proxy, config.Timeout.Duration, config.HeaderTimeout.Duration, 1, config.UserAgent, config.requestHeaders(), nil, nil, nil) // Without the credentials or TLS overrides, which belong to the SDS site only
+		if err != nil {
+			slog.Error("failed to configure proxy", "error", err)
+		} else if err := notifyWebhook(context.WithoutCancel(ctx), notifyClient, config.NotifyURL, summary); err != nil {
+			slog.Error("failed to send notification", "url", config.NotifyURL, "error", err)
+		}
 	}
-	defer out.Close() // Ensure file is closed
 
-	_, err = buf.WriteTo(out) // Write buffer content to file
-	if err != nil {           // Handle write error
-		log.Printf("failed to write PDF to file for %s: %v", finalURL, err)
-		return
+	if failed := summary.Failed(); failed > config.MaxFailures || (config.FailFast && failed > 0) { // Let cron and CI notice the failures
+		slog.Error("downloads failed", "failed", failed, "max_failures", config.MaxFailures)
+		os.Exit(1)
 	}
+}
 
-	log.Printf("successfully downloaded %d bytes: %s → %s\n", written, finalURL, filePath) // Log success
+// canonicalLink normalizes link and resolves it against baseURL so equivalent spellings compare equal;
+// a link that doesn't parse is logged and kept as written, for the downloader to report
+func canonicalLink(link, baseURL string) string {
+	canonical, err := scraper.AbsolutizeLink(scraper.NormalizeURL(link, baseURL), baseURL)
+	if err != nil {
+		slog.Warn("error parsing URL", "url", link, "base_url", baseURL, "error", err)
+	}
+	return canonical
 }
 
-// readAFileAsString reads a file from disk and returns its contents as a string
-func readAFileAsString(path string) string {
-	content, err := os.ReadFile(path) // Read file contents
-	if err != nil {                   // Handle file read error
-		log.Println(err)
+// linkWanted reports whether link passes -include, -exclude, -allow-host and robots.txt, logging why not
+func linkWanted(ctx context.Context, pageScraper *scraper.Scraper, link string, includePattern, excludePattern *regexp.Regexp, allowedHosts []string) bool {
+	if !matchesFilters(link, includePattern, excludePattern) { // Apply -include and -exclude
+		slog.Debug("link filtered out", "url", link)
+		return false
+	}
+	if !scraper.HostAllowed(link, allowedHosts) { // Off-site links need an explicit -allow-host
+		slog.Log(ctx, storage.LevelSkip, "link on a host that isn't allowed, skipping", "url", link)
+		return false
+	}
+	if !pageScraper.RobotsAllowed(ctx, link) { // Skip PDFs robots.txt disallows
+		slog.Log(ctx, storage.LevelSkip, "robots.txt disallows download, skipping", "url", link)
+		return false
 	}
-	return string(content) // Return content as string
+	return true
 }
 
-// removeDuplicatesFromSlice removes duplicate entries from a string slice
-func removeDuplicatesFromSlice(slice []string) []string {
-	check := make(map[string]bool)  // Create map to track seen strings
-	var newReturnSlice []string     // Slice to hold unique entries
-	for _, content := range slice { // Iterate through input slice
-		if !check[content] { // If string not seen before
-			check[content] = true                            // Mark as seen
-			newReturnSlice = append(newReturnSlice, content) // Add to result slice
+// filterLinksSince keeps the links the link store first saw on or after since
+func filterLinksSince(links []string, dbPath, linkFile string, since time.Time, strict bool) ([]string, error) {
+	trackedLinks, err := storage.OpenLinkIndex(dbPath, linkFile) // First-seen times from earlier runs
+	if err != nil {                                              // Handle an unreadable store
+		return nil, err
+	}
+	defer trackedLinks.Close() // Release the database, if one is used
+
+	var recentLinks []string     // Links that pass the filter
+	for _, link := range links { // Check each link's first-seen time
+		if trackedLinks.SeenSince(link, since, strict) {
+			recentLinks = append(recentLinks, link)
 		}
 	}
-	return newReturnSlice // Return deduplicated slice
+	slog.Info("applied since filter", "since", since.Format(sinceLayout), "kept", len(recentLinks), "skipped", len(links)-len(recentLinks))
+	return recentLinks, nil
 }
 
-// createDirectory creates a new directory with specified permissions
-func createDirectory(path string, permission os.FileMode) {
-	err := os.Mkdir(path, permission) // Attempt to create directory
-	if err != nil {                   // Handle error
-		log.Println(err)
+// filterLinksNewerThan keeps the links whose revision date on the page is after newerThan, along with
+// the links the page gave no date for
+func filterLinksNewerThan(ctx context.Context, links []string, labels map[string]storage.LinkLabel, newerThan time.Time) []string {
+	var revisedLinks []string // Links revised after the cutoff, or undated
+	undated := 0              // Links kept for lack of a date
+	for _, link := range links {
+		if labels[link].RevisionDate == "" {
+			undated++
+		}
+		if revisedAfter(ctx, link, labels[link], newerThan) {
+			revisedLinks = append(revisedLinks, link)
+		}
 	}
+	slog.Info("applied newer than filter", "newer_than", newerThan.Format(sinceLayout), "kept", len(revisedLinks), "undated", undated, "skipped", len(links)-len(revisedLinks))
+	return revisedLinks
 }
 
-// directoryExists returns true if a directory exists at the given path
-func directoryExists(path string) bool {
-	directory, err := os.Stat(path) // Get file/directory info
-	if err != nil {                 // If stat fails
-		return false
+// revisedAfter reports whether label's revision date is after newerThan or unknown, logging the links it rejects
+func revisedAfter(ctx context.Context, link string, label storage.LinkLabel, newerThan time.Time) bool {
+	if label.RevisionDate == "" || label.RevisionDate > newerThan.Format(scraper.RevisionLayout) { // Both YYYY-MM-DD, so they sort as text
+		return true
 	}
-	return directory.IsDir() // Return true if it's a directory
+	slog.Log(ctx, storage.LevelSkip, "revision not newer than -newer-than, skipping", "url", link, "revision_date", label.RevisionDate)
+	return false
 }
 
-// fileExists returns true if a file exists at the given path
-func fileExists(filename string) bool {
-	info, err := os.Stat(filename) // Get file info
-	if err != nil {                // If stat fails
+// matchesFilters reports whether link matches include (when set) and doesn't match exclude (when set)
+func matchesFilters(link string, include, exclude *regexp.Regexp) bool {
+	if include != nil && !include.MatchString(link) { // Not one of the wanted URLs
 		return false
 	}
-	return !info.IsDir() // Return true if it's a file, not directory
+	return exclude == nil || !exclude.MatchString(link)
 }
 
-// appendAndWriteToFile appends content to a file or creates it if it doesn't exist
-func appendAndWriteToFile(path string, content string) {
-	filePath, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) // Open file with append/create/write flags
-	if err != nil {                                                               // Handle file open error
-		log.Println(err)
+// printDryRun lists each link with whether its PDF is already on disk, followed by a count summary
+func printDryRun(links []string, outputDir string, nameTemplate *template.Template, partition string) {
+	previousRecords, err := storage.ReadManifest(filepath.Join(outputDir, storage.ManifestFile)) // Earlier downloads, for filename ownership
+	if err != nil {                                                                              // Fall back to plain names
+		slog.Warn("failed to read manifest", "error", err)
 	}
-	_, err = filePath.WriteString(content + "\n") // Write content with newline
-	if err != nil {                               // Handle write error
-		log.Println(err)
+	registry := storage.NewFilenameRegistry(previousRecords, nameTemplate, partition) // Resolves filename collisions the same way DownloadAll does
+
+	existingCount := 0           // Links whose file is already downloaded
+	for _, link := range links { // Report every link that would be downloaded
+		filePath := registry.PathFor(outputDir, link) // Where the PDF would be saved
+		status := "new"                               // Not on disk yet
+		if storage.FileExists(filePath) {             // Already downloaded
+			status = "exists"
+			existingCount++
+		}
+		fmt.Printf("%-6s %s\n", status, link) // One line per link
 	}
-	err = filePath.Close() // Close the file
-	if err != nil {        // Handle close error
-		log.Println(err)
+	fmt.Printf("%d links: %d already downloaded, %d new\n", len(links), existingCount, len(links)-existingCount) // Count summary
+}
+
+// printLinksJSON writes links to stdout as a JSON array, always [] rather than null when empty
+func printLinksJSON(links []string) error {
+	if links == nil { // Encode no links as an empty array
+		links = []string{}
 	}
+	encoder := json.NewEncoder(os.Stdout) // Stdout carries only the JSON; logs go to stderr
+	encoder.SetIndent("", "  ")           // One URL per line
+	return encoder.Encode(links)
+}
+
+// removeDuplicates removes repeated entries from a slice, keeping the first occurrence of each in order
+func removeDuplicates[T comparable](slice []T) []T {
+	check := make(map[T]bool)       // Create map to track seen entries
+	var newReturnSlice []T          // Slice to hold unique entries
+	for _, content := range slice { // Iterate through input slice
+		if !check[content] { // If entry not seen before
+			check[content] = true                            // Mark as seen
+			newReturnSlice = append(newReturnSlice, content) // Add to result slice
+		}
+	}
+	return newReturnSlice // Return deduplicated slice
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemoveDuplicatesStrings(t *testing.T) {
+	got := removeDuplicates([]string{"b.pdf", "a.pdf", "b.pdf", "c.pdf", "a.pdf"})
+	if want := []string{"b.pdf", "a.pdf", "c.pdf"}; !slices.Equal(got, want) {
+		t.Errorf("removeDuplicates = %q, want %q", got, want)
+	}
+	if got := removeDuplicates([]string(nil)); len(got) != 0 {
+		t.Errorf("removeDuplicates(nil) = %q, want empty", got)
+	}
+}
+
+func TestRemoveDuplicatesStructs(t *testing.T) {
+	type hostPath struct {
+		host string
+		path string
+	}
+	got := removeDuplicates([]hostPath{
+		{"www.duragloss.com", "/a.pdf"},
+		{"cdn.duragloss.com", "/a.pdf"}, // Same path, different host: kept
+		{"www.duragloss.com", "/a.pdf"},
+	})
+	want := []hostPath{{"www.duragloss.com", "/a.pdf"}, {"cdn.duragloss.com", "/a.pdf"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("removeDuplicates = %v, want %v", got, want)
+	}
+}
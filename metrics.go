@@ -0,0 +1,74 @@
+package main // Prometheus metrics for long-running and scheduled deployments
+
+import (
+	"errors"   // For recognizing a normal server shutdown
+	"fmt"      // For wrapping errors
+	"log/slog" // For structured logging
+	"net"      // For binding the listener up front
+	"net/http" // For the metrics server
+	"time"     // For download durations
+
+	"github.com/prometheus/client_golang/prometheus"          // Metric types and the default registry
+	"github.com/prometheus/client_golang/prometheus/promhttp" // The /metrics handler
+)
+
+// runMetrics holds the collectors -metrics-addr exposes
+type runMetrics struct {
+	downloads       *prometheus.CounterVec // Links settled by the download pool, by result
+	bytesDownloaded prometheus.Counter     // Bytes of newly saved files
+	duration        prometheus.Histogram   // Time spent fetching each link, retries included
+	linksDiscovered prometheus.Gauge       // Links queued for download after filtering
+}
+
+// newRunMetrics creates the collectors and registers them with the default registry
+func newRunMetrics() *runMetrics {
+	metrics := &runMetrics{
+		downloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "downloads_total",
+			Help: "Links settled by the download pool, by result.",
+		}, []string{"result"}),
+		bytesDownloaded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bytes_downloaded_total",
+			Help: "Bytes of newly downloaded files.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "download_duration_seconds",
+			Help:    "Time spent downloading each link, retries included.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to about 100s
+		}),
+		linksDiscovered: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "links_discovered",
+			Help: "Document links queued for download after filtering.",
+		}),
+	}
+	prometheus.MustRegister(metrics.downloads, metrics.bytesDownloaded, metrics.duration, metrics.linksDiscovered)
+	return metrics
+}
+
+// observeDownload records one link's outcome; it matches downloader.Downloader.OnResult
+func (metrics *runMetrics) observeDownload(result string, bytes int64, elapsed time.Duration) {
+	metrics.downloads.WithLabelValues(result).Inc()
+	metrics.bytesDownloaded.Add(float64(bytes))
+	if elapsed > 0 { // Links skipped without a request have no duration
+		metrics.duration.Observe(elapsed.Seconds())
+	}
+}
+
+// serveMetrics starts serving /metrics on addr in the background; binding happens before it
+// returns so a taken port fails the run instead of going unnoticed
+func serveMetrics(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler()) // Default registry, Go runtime and process metrics included
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", listener.Addr().String())
+	return server, nil
+}
@@ -0,0 +1,114 @@
+package main // Browsable HTML index of the downloaded archive
+
+import (
+	"fmt"           // For formatted error messages
+	"html/template" // For escaping titles and paths in the page
+	"log/slog"      // For structured logging
+	"os"            // For writing the index
+	"path/filepath" // For relative links to the saved files
+	"sort"          // For grouping entries in a stable order
+	"time"          // For the generation timestamp
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // Byte size formatting
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"    // Download manifest
+)
+
+// mirrorIndexFile is the name of the index page written next to the downloaded files
+const mirrorIndexFile = "index.html"
+
+// mirrorIndexTemplate lists the saved files in one table, grouped by category
+var mirrorIndexTemplate = template.Must(template.New(mirrorIndexFile).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SDS archive</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; }
+td.size { text-align: right; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>SDS archive</h1>
+<p>{{len .Entries}} files, generated {{.Generated.Format "2006-01-02 15:04 MST"}}</p>
+<table>
+<tr><th>Category</th><th>Title</th><th>File</th><th>Size</th><th>Downloaded</th></tr>
+{{- range .Entries}}
+<tr><td>{{.Category}}</td><td>{{.Title}}</td><td><a href="{{.Href}}">{{.Name}}</a></td><td class="size">{{.Size}}</td><td>{{.Downloaded.Format "2006-01-02"}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// mirrorIndexEntry is one row of the index page
+type mirrorIndexEntry struct {
+	Category   string    // Section heading from the scraped page
+	Title      string    // Product name from the page, else the PDF's own title
+	Name       string    // File name shown as the link text
+	Href       string    // Path of the file relative to the index
+	Size       string    // Human-readable file size
+	Downloaded time.Time // When the file was last fetched
+}
+
+// writeMirrorIndex writes index.html into outputDir with a row for every file in the manifest that's
+// still on disk, replacing the page from the previous run
+func writeMirrorIndex(outputDir string) error {
+	records, err := storage.ReadManifest(filepath.Join(outputDir, storage.ManifestFile)) // Everything downloaded so far
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool) // Files already listed; duplicate URLs share one file
+	var entries []mirrorIndexEntry
+	for _, record := range records {
+		if seen[record.Filename] || !storage.FileExists(record.Filename) { // Listed already, or pruned since
+			continue
+		}
+		seen[record.Filename] = true
+		relative, err := filepath.Rel(outputDir, record.Filename)
+		if err != nil { // Saved outside the output directory
+			continue
+		}
+		title := record.LinkTitle
+		if title == "" { // Fall back to the PDF metadata
+			title = record.Title
+		}
+		entries = append(entries, mirrorIndexEntry{
+			Category:   record.Category,
+			Title:      title,
+			Name:       filepath.Base(record.Filename),
+			Href:       filepath.ToSlash(relative),
+			Size:       downloader.FormatByteSize(record.Size),
+			Downloaded: record.DownloadedAt,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { // By category, then title, then file name
+		if entries[i].Category != entries[j].Category {
+			return entries[i].Category < entries[j].Category
+		}
+		if entries[i].Title != entries[j].Title {
+			return entries[i].Title < entries[j].Title
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	indexPath := filepath.Join(outputDir, mirrorIndexFile)
+	file, err := os.Create(indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to create mirror index %s: %w", indexPath, err)
+	}
+	err = mirrorIndexTemplate.Execute(file, struct {
+		Entries   []mirrorIndexEntry
+		Generated time.Time
+	}{entries, time.Now()})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write mirror index %s: %w", indexPath, err)
+	}
+	slog.Info("wrote mirror index", "file", indexPath, "files", len(entries))
+	return nil
+}
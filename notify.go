@@ -0,0 +1,72 @@
+package main // Completion webhook for unattended runs
+
+import (
+	"bytes"         // For the request body
+	"context"       // For bounding the webhook requests
+	"encoding/json" // For encoding the summary
+	"fmt"           // For formatted error messages
+	"io"            // For draining response bodies
+	"log/slog"      // For structured logging
+	"net/http"      // For posting to the webhook
+	"time"          // For the retry backoff
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // Run summary
+)
+
+// notifyAttempts is how many times the webhook POST is tried before giving up
+const notifyAttempts = 3
+
+// notification is the JSON body posted to -notify-url
+type notification struct {
+	downloader.Summary         // Counts, new files and failures
+	ElapsedSeconds     float64 `json:"elapsed_seconds"` // Wall time of the run
+}
+
+// notifyWebhook POSTs the run summary as JSON to notifyURL, retrying network errors and 5xx or 429 answers
+func notifyWebhook(ctx context.Context, client *http.Client, notifyURL string, summary downloader.Summary) error {
+	body, err := json.Marshal(notification{Summary: summary, ElapsedSeconds: summary.Elapsed.Seconds()}) // Encode the payload once
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	backoff := 2 * time.Second // Delay before the first retry
+	for attempt := 1; ; attempt++ {
+		retryable, err := postNotification(ctx, client, notifyURL, body) // Send the summary
+		if err == nil {                                                  // Delivered
+			slog.Info("sent notification", "url", notifyURL)
+			return nil
+		}
+		if !retryable || attempt == notifyAttempts { // Permanent failure or out of attempts
+			return err
+		}
+		slog.Warn("notification failed, retrying", "url", notifyURL, "attempt", attempt, "delay", backoff, "error", err)
+
+		select {
+		case <-ctx.Done(): // Stop retrying if the context is cancelled
+			return fmt.Errorf("notification cancelled: %w", ctx.Err())
+		case <-time.After(backoff): // Wait out the backoff
+		}
+		backoff *= 2 // Double the delay for the next attempt
+	}
+}
+
+// postNotification sends one webhook request and reports whether a failure is worth retrying
+func postNotification(ctx context.Context, client *http.Client, notifyURL string, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, notifyURL, bytes.NewReader(body)) // Build the POST
+	if err != nil {                                                                                    // A bad URL won't get better
+		return false, fmt.Errorf("failed to build notification request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json") // Webhooks expect JSON
+
+	resp, err := client.Do(request) // Send the request
+	if err != nil {                 // Network error: worth another try
+		return true, fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()        // Ensure response body is closed
+	io.Copy(io.Discard, resp.Body) // Drain so the connection can be reused
+	if resp.StatusCode/100 == 2 {  // Any 2xx means delivered
+		return false, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests // Server trouble may clear up
+	return retryable, fmt.Errorf("notification rejected: %s", resp.Status)
+}
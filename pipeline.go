@@ -0,0 +1,74 @@
+package main // Scraping and downloading at the same time
+
+import (
+	"context"  // For cancelling discovery and downloads together
+	"log/slog" // For structured logging
+	"os"       // For exiting when discovery can't start
+	"sync"     // For collecting links from seeds scraped in parallel
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // PDF downloads
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/scraper"    // Page scraping and link discovery
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"    // Link list files
+)
+
+// pipelineBacklog is how many discovered links may wait for a download worker before scraping pauses,
+// so a fast crawl can't run arbitrarily far ahead of slow downloads
+const pipelineBacklog = 256
+
+// runPipeline scrapes the seeds and sitemap while downloading what they yield: each seed's links are
+// filtered and handed to the download workers as soon as that seed is done. DownloadStream drops the
+// links found more than once, so seeds that share documents don't download them twice. The full link
+// list is saved once discovery finishes, as after a phased run.
+func runPipeline(ctx context.Context, pageScraper *scraper.Scraper, pdfDownloader *downloader.Downloader, config Config) downloader.Summary {
+	includePattern, excludePattern, _ := config.linkPatterns() // Validated with the rest of the config
+	allowedHosts := config.allowedHosts()                      // Hosts PDFs may come from
+	newerThan, _ := config.newerThanDate()                     // Zero when -newer-than is off
+
+	links := make(chan string, pipelineBacklog) // Wanted links, in discovery order
+	summaries := make(chan downloader.Summary, 1)
+	go func() {
+		summaries <- pdfDownloader.DownloadStream(ctx, links, config.OutputDir, config.Workers, config.Attempts, config.Quiet || config.Verbosity < 0)
+	}()
+
+	var foundMutex sync.Mutex // Seeds scraped in parallel report at once
+	var foundLinks []string   // Every canonical link, for the link list
+	_, labels, err := discoverLinks(ctx, pageScraper, config, func(batch []string, batchLabels map[string]storage.LinkLabel) {
+		pdfDownloader.AddLabels(batchLabels) // Before the links, so their manifest records get them
+		canonicalLinks := make([]string, 0, len(batch))
+		for _, link := range batch { // Canonicalize links so equivalent spellings dedupe together
+			canonicalLinks = append(canonicalLinks, canonicalLink(link, config.BaseURL))
+		}
+		foundMutex.Lock()
+		foundLinks = append(foundLinks, canonicalLinks...)
+		foundMutex.Unlock()
+		for _, link := range canonicalLinks {
+			if !linkWanted(ctx, pageScraper, link, includePattern, excludePattern, allowedHosts) {
+				continue
+			}
+			if !newerThan.IsZero() && !revisedAfter(ctx, link, batchLabels[link], newerThan) {
+				continue
+			}
+			select {
+			case links <- link: // Waits while the workers are this far behind
+			case <-ctx.Done(): // Interrupted; DownloadStream stops taking links
+				return
+			}
+		}
+	})
+	close(links) // No more links; the workers finish what's queued
+	summary := <-summaries
+	if err != nil { // Nothing was scraped
+		slog.Error("failed to discover links", "error", err)
+		os.Exit(1)
+	}
+
+	foundLinks = removeDuplicates(foundLinks)
+	if config.LinkListFile != "" { // Keep what this scrape found for -download-only
+		if err := storage.SaveLinkList(config.LinkListFile, foundLinks, labels); err != nil {
+			slog.Error("failed to save link list", "error", err)
+		} else {
+			slog.Info("saved link list", "file", config.LinkListFile, "links", len(foundLinks))
+		}
+	}
+	return summary
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader"
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/scraper"
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"
+)
+
+// fixturePDFs are the documents the fixture site serves, by path
+var fixturePDFs = map[string][]byte{
+	"/sds/wax.pdf":        []byte("%PDF-1.4\n% wax sheet\n"),
+	"/sds/polish.pdf":     []byte("%PDF-1.4\n% polish sheet\n"),
+	"/private/secret.pdf": []byte("%PDF-1.4\n% not for robots\n"),
+}
+
+// fixtureIndex is the SDS index page, linking every PDF in fixturePDFs
+const fixtureIndex = `<html><body><h2>Waxes</h2><table>
+<tr><td>Paste Wax</td><td><a href="/sds/wax.pdf">SDS</a></td></tr>
+<tr><td>Polish</td><td><a href="/sds/polish.pdf?v=2">SDS</a></td></tr>
+<tr><td>Secret</td><td><a href="/private/secret.pdf#page=1">SDS</a></td></tr>
+</table></body></html>`
+
+// newFixtureSite serves fixtureIndex at /sds/, the PDFs, and a robots.txt that keeps /private/ off limits
+func newFixtureSite(t *testing.T) *httptest.Server {
+	site := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/robots.txt":
+			io.WriteString(writer, "User-agent: *\nDisallow: /private/\n")
+		case "/sds/":
+			writer.Header().Set("Content-Type", "text/html")
+			io.WriteString(writer, fixtureIndex)
+		default:
+			content, found := fixturePDFs[request.URL.Path]
+			if !found {
+				http.NotFound(writer, request)
+				return
+			}
+			writer.Header().Set("Content-Type", "application/pdf")
+			writer.Write(content)
+		}
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func TestPipelineDownloadsFixtureSite(t *testing.T) {
+	site := newFixtureSite(t)
+	workDir := t.TempDir()
+	t.Chdir(workDir) // The seed page's HTML cache is written to the working directory
+	previousLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+
+	outputDir := filepath.Join(workDir, "PDFs")
+	config, err := parseConfig("test", []string{
+		"-url", site.URL + "/sds/",
+		"-base-url", site.URL + "/",
+		"-sitemap", "",
+		"-no-chrome",
+		"-pipeline",
+		"-quiet",
+		"-out", outputDir,
+		"-links", filepath.Join(workDir, "links.txt"),
+		"-hash-index", filepath.Join(workDir, "hashes.json"),
+		"-link-list", filepath.Join(workDir, "found.json"),
+	})
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	httpClient, err := newHTTPClient("", config.Timeout.Duration, config.HeaderTimeout.Duration, 2, config.UserAgent, config.requestHeaders(), config.credentialHeaders(), config.credentialHosts(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashIndex, err := storage.LoadContentHashIndex(config.HashIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageScraper := scraper.New(httpClient, config.UserAgent, config.IgnoreRobots)
+	pdfDownloader := &downloader.Downloader{
+		HTTPClient:  httpClient,
+		RateLimiter: downloader.NewHostRateLimiter(0),
+		HashIndex:   hashIndex,
+		LinkFile:    config.LinkFile,
+	}
+
+	summary := runPipeline(context.Background(), pageScraper, pdfDownloader, config)
+
+	if summary.Downloaded != 2 || summary.Failed() != 0 {
+		t.Errorf("downloaded %d with %d failures, want 2 and none: %+v", summary.Downloaded, summary.Failed(), summary)
+	}
+	if cached, err := os.ReadFile(defaultHTMLCache); err != nil || string(cached) != fixtureIndex {
+		t.Errorf("cached seed page = %q (%v), want the index page as served", cached, err)
+	}
+	records, err := storage.ReadManifest(filepath.Join(outputDir, storage.ManifestFile))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	byURL := storage.RecordsByURL(records)
+	if _, found := byURL[site.URL+"/private/secret.pdf"]; found || len(records) != 2 {
+		t.Errorf("manifest has %d records, want 2 without the robots.txt-disallowed PDF", len(records))
+	}
+	tests := []struct {
+		url   string
+		path  string
+		title string
+	}{
+		{site.URL + "/sds/wax.pdf", "/sds/wax.pdf", "Paste Wax"},
+		{site.URL + "/sds/polish.pdf?v=2", "/sds/polish.pdf", "Polish"},
+	}
+	for _, test := range tests {
+		record, found := byURL[test.url]
+		if !found {
+			t.Errorf("manifest has no record for %s; it has %d records", test.url, len(records))
+			continue
+		}
+		saved, err := os.ReadFile(record.Filename)
+		if err != nil {
+			t.Errorf("reading %s: %v", record.Filename, err)
+			continue
+		}
+		if !bytes.Equal(saved, fixturePDFs[test.path]) {
+			t.Errorf("%s holds %q, want %q", record.Filename, saved, fixturePDFs[test.path])
+		}
+		digest := sha256.Sum256(fixturePDFs[test.path])
+		if record.SHA256 != hex.EncodeToString(digest[:]) || record.Size != int64(len(fixturePDFs[test.path])) {
+			t.Errorf("record for %s has sha256 %s and size %d, want the served file's", test.url, record.SHA256, record.Size)
+		}
+		if record.LinkTitle != test.title || record.Category != "Waxes" {
+			t.Errorf("record for %s is labelled %q in %q, want %q in Waxes", test.url, record.LinkTitle, record.Category, test.title)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main // Cleanup of downloaded files whose SDS sheets left the site
+
+import (
+	"errors"        // For skipping files that are already gone
+	"fmt"           // For the prune report
+	"io/fs"         // For the not-exist sentinel error
+	"log/slog"      // For structured logging
+	"os"            // For removing files
+	"path/filepath" // For locating the manifest
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Download manifest
+)
+
+// pruneStaleFiles lists the manifest's files whose source URLs aren't among liveLinks; with remove set it
+// deletes them and drops their records. A file shared with a still-linked URL is always kept.
+func pruneStaleFiles(outputDir string, liveLinks []string, remove bool) error {
+	if len(liveLinks) == 0 { // A failed scrape would otherwise look like every sheet was withdrawn
+		return errors.New("no links were extracted; refusing to prune")
+	}
+	manifestPath := filepath.Join(outputDir, storage.ManifestFile) // Manifest lives next to the PDFs
+	records, err := storage.ReadManifest(manifestPath)             // What earlier runs saved
+	if err != nil {
+		return err
+	}
+
+	live := make(map[string]bool, len(liveLinks)) // URLs still linked from the site
+	for _, link := range liveLinks {
+		live[link] = true
+	}
+	inUse := make(map[string]bool) // Files a live URL still points at
+	for _, record := range records {
+		if live[record.URL] {
+			inUse[record.Filename] = true
+		}
+	}
+
+	kept := make([]storage.DownloadRecord, 0, len(records)) // Records that survive the prune; [] rather than null when empty
+	pruned := 0                                             // Records whose URL is gone
+	for _, record := range records {
+		if live[record.URL] { // Still on the site
+			kept = append(kept, record)
+			continue
+		}
+		pruned++
+		if inUse[record.Filename] { // Duplicate content of a sheet that's still listed
+			fmt.Printf("%-6s %s (shared with a listed URL)\n", "keep", record.Filename)
+			continue
+		}
+		if !remove { // Report only
+			fmt.Printf("%-6s %s %s\n", "prune", record.Filename, record.URL)
+			continue
+		}
+		if err := os.Remove(record.Filename); err != nil && !errors.Is(err, fs.ErrNotExist) { // Keep the record so a later run can retry
+			slog.Error("failed to remove stale file", "file", record.Filename, "error", err)
+			kept = append(kept, record)
+			continue
+		}
+		fmt.Printf("%-6s %s %s\n", "pruned", record.Filename, record.URL)
+	}
+
+	if !remove { // Nothing changed on disk
+		fmt.Printf("%d of %d downloaded files are no longer linked\n", pruned, len(records))
+		return nil
+	}
+	if err := storage.WriteManifest(kept, manifestPath); err != nil { // Forget the removed files
+		return err
+	}
+	fmt.Printf("removed %d of %d downloaded files no longer linked\n", len(records)-len(kept), len(records))
+	return nil
+}
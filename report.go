@@ -0,0 +1,172 @@
+package main // Exportable run summary for dashboards, tickets and other tools
+
+import (
+	"bytes"         // For building the report before writing it
+	"encoding/json" // For the JSON report
+	"fmt"           // For the Markdown report
+	"html/template" // For the HTML report
+	"log/slog"      // For structured logging
+	"os"            // For writing the report
+	"sort"          // For listing failure reasons in a stable order
+	"strings"       // For escaping Markdown table cells
+	"time"          // For the report timestamp
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // Run summary
+)
+
+// Formats accepted by -report
+const (
+	reportJSON     = "json" // For machines
+	reportMarkdown = "md"   // For pasting into tickets
+	reportHTML     = "html" // For a dashboard
+)
+
+// reportPath returns where the report is written: ReportFile, or report.<format> when it's empty
+func (config Config) reportPath() string {
+	if config.ReportFile != "" {
+		return config.ReportFile
+	}
+	return "report." + config.Report
+}
+
+// writeReport writes the run summary to path in format, replacing any earlier report
+func writeReport(path, format string, summary downloader.Summary) error {
+	var content bytes.Buffer
+	var err error
+	switch format {
+	case reportJSON: // Same payload the webhook receives
+		var encoded []byte
+		encoded, err = json.MarshalIndent(notification{Summary: summary, ElapsedSeconds: summary.Elapsed.Seconds()}, "", "  ")
+		content.Write(encoded)
+	case reportMarkdown:
+		writeMarkdownReport(&content, summary)
+	case reportHTML:
+		err = htmlReportTemplate.Execute(&content, newReportView(summary))
+	default: // Validated with the rest of the config
+		err = fmt.Errorf("unknown report format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build %s report: %w", format, err)
+	}
+	if err := os.WriteFile(path, content.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	slog.Info("wrote run report", "file", path, "format", format)
+	return nil
+}
+
+// reportCount is one labelled number in the report
+type reportCount struct {
+	Label string
+	Value string
+}
+
+// reportView is what the Markdown and HTML reports show
+type reportView struct {
+	Generated time.Time            // When the report was written
+	Counts    []reportCount        // Totals, in display order
+	Reasons   []reportCount        // Failure count per reason, alphabetically
+	Failures  []downloader.Failure // Every failed link with its reason
+	NewFiles  []string             // Files saved this run
+}
+
+// newReportView flattens the summary into rows for the text reports
+func newReportView(summary downloader.Summary) reportView {
+	view := reportView{
+		Generated: time.Now(),
+		Counts: []reportCount{
+			{"Links found", fmt.Sprint(summary.LinksFound)},
+			{"Downloaded", fmt.Sprint(summary.Downloaded)},
+			{"Already present", fmt.Sprint(summary.AlreadyPresent)},
+			{"Already processed", fmt.Sprint(summary.AlreadyProcessed)},
+			{"Deferred", fmt.Sprint(summary.Deferred)},
+			{"Failed", fmt.Sprint(summary.Failed())},
+			{"Bytes written", fmt.Sprintf("%d (%s)", summary.BytesWritten, downloader.FormatByteSize(summary.BytesWritten))},
+			{"Duration", summary.Elapsed.Round(time.Millisecond).String()},
+		},
+		Failures: summary.FailureDetails,
+		NewFiles: summary.NewFiles,
+	}
+	for reason, count := range summary.Failures {
+		view.Reasons = append(view.Reasons, reportCount{reason, fmt.Sprint(count)})
+	}
+	sort.Slice(view.Reasons, func(i, j int) bool { return view.Reasons[i].Label < view.Reasons[j].Label })
+	return view
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// writeMarkdownReport renders the summary as Markdown tables and lists
+func writeMarkdownReport(out *bytes.Buffer, summary downloader.Summary) {
+	view := newReportView(summary)
+	fmt.Fprintf(out, "# SDS download run\n\nGenerated %s\n\n", view.Generated.Format("2006-01-02 15:04 MST"))
+	out.WriteString("| | |\n|---|---:|\n")
+	for _, count := range view.Counts {
+		fmt.Fprintf(out, "| %s | %s |\n", count.Label, count.Value)
+	}
+	if len(view.Failures) > 0 {
+		out.WriteString("\n## Failures\n\n")
+		for _, reason := range view.Reasons {
+			fmt.Fprintf(out, "- %s: %s\n", reason.Label, reason.Value)
+		}
+		out.WriteString("\n| Reason | URL | Error |\n|---|---|---|\n")
+		for _, failure := range view.Failures {
+			fmt.Fprintf(out, "| %s | %s | %s |\n", markdownCell(failure.Reason), markdownCell(failure.URL), markdownCell(failure.Error))
+		}
+	}
+	if len(view.NewFiles) > 0 {
+		out.WriteString("\n## New files\n\n")
+		for _, file := range view.NewFiles {
+			fmt.Fprintf(out, "- `%s`\n", file)
+		}
+	}
+}
+
+// htmlReportTemplate renders the summary as a standalone page
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SDS download run</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<h1>SDS download run</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04 MST"}}</p>
+<table>
+{{- range .Counts}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Failures}}
+<h2>Failures</h2>
+<table>
+{{- range .Reasons}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+<table>
+<tr><th>Reason</th><th>URL</th><th>Error</th></tr>
+{{- range .Failures}}
+<tr><td>{{.Reason}}</td><td><a href="{{.URL}}">{{.URL}}</a></td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .NewFiles}}
+<h2>New files</h2>
+<ul>
+{{- range .NewFiles}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,249 @@
+package scraper // Headless Chrome rendering of the SDS index page
+
+import (
+	"context"  // For the Chrome listener context
+	"errors"   // For the timeout sentinel
+	"fmt"      // For wrapping errors
+	"log/slog" // For structured logging
+	"net/url"  // For the proxy URL and credential hosts
+	"strings"  // For comparing host and header names
+	"time"     // For working with time durations and timestamps
+
+	"github.com/chromedp/cdproto/fetch"   // Chrome DevTools request interception, used for proxy auth
+	"github.com/chromedp/cdproto/network" // Chrome DevTools network domain, used for extra request headers
+	"github.com/chromedp/chromedp"        // Headless Chrome/Chromium browser automation
+)
+
+// ChromeOptions controls how headless Chrome loads and captures a page
+type ChromeOptions struct {
+	Proxy        *url.URL          // Proxy for Chrome's traffic; nil connects directly
+	WaitSelector string            // CSS selector that must be visible before the HTML is captured; empty skips the wait
+	WaitTimeout  time.Duration     // How long to wait for WaitSelector before capturing the page as is
+	Timeout      time.Duration     // Bound on the whole Chrome session, from launch to captured HTML
+	ExecPath     string            // Chrome executable to launch; empty lets chromedp search the usual locations
+	UserDataDir  string            // Profile directory reused across runs so cookies persist; empty uses a temporary profile
+	Headful      bool              // Show the browser window instead of running headless
+	RemoteURL    string            // DevTools WebSocket URL of an already running Chrome; set, nothing is launched locally
+	Headers      map[string]string // Extra headers sent with every request Chrome makes
+	InsecureTLS  bool              // Accept any TLS certificate, for hosts with self-signed certificates
+
+	Credentials     map[string]string // Headers such as Authorization added only to requests for CredentialHosts
+	CredentialHosts []string          // host[:port] values Credentials may be sent to; scripts, CDNs and redirects elsewhere never see them
+}
+
+// ErrChromeTimeout reports that Chrome didn't finish loading the page within ChromeOptions.Timeout
+var ErrChromeTimeout = errors.New("chrome timed out")
+
+// ScrapePageHTMLWithChrome uses headless Chrome to fetch fully rendered HTML from a URL; it returns
+// an error wrapping ErrChromeTimeout on timeout, and an empty string with no error when the page rendered empty
+func (scraper *Scraper) ScrapePageHTMLWithChrome(ctx context.Context, pageURL string, chrome ChromeOptions) (string, error) {
+	scraper.logger().Info("scraping page", "url", pageURL) // Log scraping action
+	started := time.Now()                                  // For reporting how long a timed-out session ran
+
+	allocatorCtx, cancelAllocator := newChromeAllocator(ctx, chrome, scraper.logger()) // Create Chrome allocator context; cancelling ctx stops Chrome
+
+	ctxTimeout, cancelTimeout := context.WithTimeout(allocatorCtx, chrome.Timeout) // Set timeout for Chrome session
+
+	browserCtx, cancelBrowser := chromedp.NewContext(ctxTimeout) // Create browser tab context
+
+	defer func() { // Ensure all contexts are cleaned up
+		cancelBrowser()
+		cancelTimeout()
+		cancelAllocator()
+	}()
+
+	return renderPage(browserCtx, pageURL, chrome, started, scraper.logger())
+}
+
+// CheckChrome launches (or connects to) Chrome with the given options and opens a blank tab,
+// so a missing or broken browser is reported before a real run depends on it; launch warnings go to logger
+func CheckChrome(ctx context.Context, chrome ChromeOptions, logger *slog.Logger) error {
+	started := time.Now()                                                          // For reporting how long a timed-out launch ran
+	allocatorCtx, cancelAllocator := newChromeAllocator(ctx, chrome, logger)       // Same launch settings as a real scrape
+	defer cancelAllocator()                                                        // Stop Chrome when done
+	ctxTimeout, cancelTimeout := context.WithTimeout(allocatorCtx, chrome.Timeout) // Bound the launch
+	defer cancelTimeout()
+	browserCtx, cancelBrowser := chromedp.NewContext(ctxTimeout) // Create browser tab context
+	defer cancelBrowser()
+
+	err := chromedp.Run(browserCtx, chromedp.Navigate("about:blank")) // Start the browser and load nothing
+	if errors.Is(browserCtx.Err(), context.DeadlineExceeded) {        // Launched too slowly
+		return chromeError(browserCtx, "about:blank", started, err)
+	}
+	if err != nil { // Missing executable, bad flags or unreachable remote browser
+		return fmt.Errorf("failed to start Chrome: %w", err)
+	}
+	return nil
+}
+
+// renderPage loads pageURL in the tab behind tabCtx, whose deadline bounds the whole render, and returns its HTML
+func renderPage(tabCtx context.Context, pageURL string, chrome ChromeOptions, started time.Time, logger *slog.Logger) (string, error) {
+	var pageHTML string // Variable to store final HTML
+
+	actions := chromeFetchActions(tabCtx, chrome)                     // Answer proxy auth challenges and add credentials first, if any were given
+	actions = append(actions, chromeHeaderActions(chrome.Headers)...) // Send the extra headers, if any
+	actions = append(actions, chromedp.Navigate(pageURL))             // Navigate to page
+	err := chromedp.Run(tabCtx, actions...)                           // Run ChromeDP tasks
+	if err != nil {                                                   // If navigation fails
+		return "", chromeError(tabCtx, pageURL, started, err)
+	}
+
+	waitForSelector(tabCtx, pageURL, chrome, logger) // Give JavaScript-rendered links time to appear
+
+	err = chromedp.Run(tabCtx, chromedp.OuterHTML("html", &pageHTML)) // Extract full page HTML
+	if err != nil {                                                   // If the DOM can't be read
+		return "", chromeError(tabCtx, pageURL, started, err)
+	}
+	return pageHTML, nil // Return the scraped HTML
+}
+
+// newChromeAllocator connects to chrome.RemoteURL when set and otherwise launches a local headless Chrome
+func newChromeAllocator(ctx context.Context, chrome ChromeOptions, logger *slog.Logger) (context.Context, context.CancelFunc) {
+	if chrome.RemoteURL != "" { // Use a Chrome running elsewhere, such as a chrome-in-docker service
+		if chrome.Proxy != nil { // Launch flags can't reach a browser that is already running
+			logger.Warn("remote Chrome doesn't use the proxy server setting; configure it on the remote browser", "remote_url", chrome.RemoteURL)
+		}
+		return chromedp.NewRemoteAllocator(ctx, chrome.RemoteURL)
+	}
+
+	options := append(chromedp.DefaultExecAllocatorOptions[:], // Create list of Chrome options
+		chromedp.Flag("headless", !chrome.Headful),    // Run Chrome in headless mode unless a window was asked for
+		chromedp.Flag("disable-gpu", true),            // Disable GPU for stability
+		chromedp.WindowSize(1920, 1080),               // Set viewport size
+		chromedp.Flag("no-sandbox", true),             // Disable sandbox (needed in some envs)
+		chromedp.Flag("disable-setuid-sandbox", true), // Disable setuid sandbox
+	)
+	if chrome.ExecPath != "" { // Non-standard install location
+		options = append(options, chromedp.ExecPath(chrome.ExecPath))
+	}
+	if chrome.InsecureTLS { // Same as the HTTP client's -insecure-skip-verify
+		options = append(options, chromedp.Flag("ignore-certificate-errors", true))
+	}
+	if chrome.UserDataDir != "" { // Persistent profile instead of a throwaway one
+		options = append(options, chromedp.UserDataDir(chrome.UserDataDir))
+	}
+	options = append(options, chromeProxyOptions(chrome.Proxy)...) // Route Chrome through the proxy, if any
+	return chromedp.NewExecAllocator(ctx, options...)
+}
+
+// chromeError turns a failed Chrome action into the error to return, wrapping ErrChromeTimeout
+// when the session deadline is what stopped it
+func chromeError(sessionCtx context.Context, pageURL string, started time.Time, err error) error {
+	if errors.Is(sessionCtx.Err(), context.DeadlineExceeded) { // The session ran out of time
+		elapsed := time.Since(started).Round(time.Millisecond) // How long Chrome ran before giving up; the caller logs the error
+		return fmt.Errorf("%w after %s loading %s", ErrChromeTimeout, elapsed, pageURL)
+	}
+	return fmt.Errorf("failed to scrape %s with Chrome: %w", pageURL, err)
+}
+
+// waitForSelector blocks until chrome.WaitSelector is visible or chrome.WaitTimeout passes;
+// a timeout is only logged so a changed page layout still yields whatever HTML rendered
+func waitForSelector(browserCtx context.Context, pageURL string, chrome ChromeOptions, logger *slog.Logger) {
+	if chrome.WaitSelector == "" { // Waiting disabled
+		return
+	}
+	waitCtx, cancelWait := context.WithTimeout(browserCtx, chrome.WaitTimeout) // Bound the wait, not the whole session
+	defer cancelWait()
+
+	err := chromedp.Run(waitCtx, chromedp.WaitVisible(chrome.WaitSelector, chromedp.ByQuery)) // Wait for the selector to render
+	if err != nil {                                                                           // Selector never showed up
+		logger.Warn("wait selector not visible, capturing page as is", "url", pageURL, "selector", chrome.WaitSelector, "timeout", chrome.WaitTimeout, "error", err)
+	}
+}
+
+// chromeProxyOptions returns the allocator option that routes Chrome through proxy
+func chromeProxyOptions(proxy *url.URL) []chromedp.ExecAllocatorOption {
+	if proxy == nil { // Direct connection
+		return nil
+	}
+	server := proxy.Scheme + "://" + proxy.Host // Chrome's --proxy-server doesn't accept credentials
+	return []chromedp.ExecAllocatorOption{chromedp.ProxyServer(server)}
+}
+
+// chromeHeaderActions makes Chrome send headers with every request
+func chromeHeaderActions(headers map[string]string) []chromedp.Action {
+	if len(headers) == 0 { // Nothing to add
+		return nil
+	}
+	extra := make(network.Headers, len(headers)) // DevTools takes arbitrary JSON values
+	for name, value := range headers {
+		extra[name] = value
+	}
+	return []chromedp.Action{network.Enable(), network.SetExtraHTTPHeaders(extra)}
+}
+
+// chromeFetchActions intercepts Chrome's requests to answer proxy authentication challenges with the proxy
+// URL's credentials and to add chrome.Credentials to the requests for chrome.CredentialHosts; without proxy
+// credentials only requests for those hosts are intercepted
+func chromeFetchActions(browserCtx context.Context, chrome ChromeOptions) []chromedp.Action {
+	proxyAuth := chrome.Proxy != nil && chrome.Proxy.User != nil                     // Proxy credentials to supply
+	addCredentials := len(chrome.Credentials) > 0 && len(chrome.CredentialHosts) > 0 // Site credentials to supply
+	if !proxyAuth && !addCredentials {                                               // Nothing to intercept
+		return nil
+	}
+	var username, password string // Proxy user name and password, possibly empty
+	if proxyAuth {
+		username = chrome.Proxy.User.Username()
+		password, _ = chrome.Proxy.User.Password()
+	}
+
+	chromedp.ListenTarget(browserCtx, func(event interface{}) { // React to intercepted requests
+		switch event := event.(type) {
+		case *fetch.EventAuthRequired: // The proxy asked for credentials
+			go chromedp.Run(browserCtx, fetch.ContinueWithAuth(event.RequestID, &fetch.AuthChallengeResponse{
+				Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+				Username: username,
+				Password: password,
+			}))
+		case *fetch.EventRequestPaused: // Add the credentials for our own hosts, and let everything else through unchanged
+			continueRequest := fetch.ContinueRequest(event.RequestID)
+			if addCredentials && credentialRequest(event.Request.URL, chrome.CredentialHosts) {
+				continueRequest = continueRequest.WithHeaders(withCredentials(event.Request.Headers, chrome.Headers, chrome.Credentials))
+			}
+			go chromedp.Run(browserCtx, continueRequest)
+		}
+	})
+
+	enable := fetch.Enable()
+	if proxyAuth { // Every request, so a challenge on any of them is answered
+		return []chromedp.Action{enable.WithHandleAuthRequests(true)}
+	}
+	patterns := make([]*fetch.RequestPattern, 0, len(chrome.CredentialHosts)) // Only the requests that need credentials
+	for _, host := range chrome.CredentialHosts {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*://" + host + "/*", RequestStage: fetch.RequestStageRequest})
+	}
+	return []chromedp.Action{enable.WithPatterns(patterns)}
+}
+
+// credentialRequest reports whether rawURL's host[:port] is one of hosts
+func credentialRequest(rawURL string, hosts []string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(parsed.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCredentials returns a paused request's headers with the extra headers and credentials set on top,
+// since a header list given to ContinueRequest replaces the request's headers entirely
+func withCredentials(original network.Headers, headers, credentials map[string]string) []*fetch.HeaderEntry {
+	merged := make(map[string]*fetch.HeaderEntry, len(original)+len(headers)+len(credentials)) // Lower-cased name -> header
+	for name, value := range original {
+		merged[strings.ToLower(name)] = &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)}
+	}
+	for _, extra := range []map[string]string{headers, credentials} { // Credentials win over everything else
+		for name, value := range extra {
+			merged[strings.ToLower(name)] = &fetch.HeaderEntry{Name: name, Value: value}
+		}
+	}
+	entries := make([]*fetch.HeaderEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	return entries
+}
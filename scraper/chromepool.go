@@ -0,0 +1,110 @@
+package scraper // Shared headless Chrome for rendering several pages
+
+import (
+	"context"  // For the browser and tab lifetimes
+	"fmt"      // For wrapping errors
+	"log/slog" // For structured logging
+	"sync"     // For guarding the browsers
+	"time"     // For timing renders
+
+	"github.com/chromedp/chromedp" // Headless Chrome/Chromium browser automation
+)
+
+// ChromePool renders pages in tabs of long-lived browsers, with at most a fixed number of tabs open
+// at once, so each page costs a tab instead of a whole browser launch. Pages behind different proxies
+// get separate browsers because the proxy is a launch flag.
+type ChromePool struct {
+	Logger *slog.Logger // Destination for the pool's logs; nil uses slog.Default()
+
+	parent   context.Context          // Lifetime of every browser; cancelling it closes them all
+	tabs     chan struct{}            // One slot per tab allowed to be open
+	mutex    sync.Mutex               // Guards browsers
+	browsers map[string]pooledBrowser // Running browsers by proxy server
+}
+
+// pooledBrowser is one running browser and the function that shuts it down
+type pooledBrowser struct {
+	ctx    context.Context    // Browser context that new tabs are opened from
+	cancel context.CancelFunc // Closes the browser and its allocator
+}
+
+// NewChromePool returns a pool that opens up to maxTabs tabs at once; browsers start on first use and
+// live until Close or until ctx is cancelled
+func NewChromePool(ctx context.Context, maxTabs int) *ChromePool {
+	return &ChromePool{
+		parent:   ctx,
+		tabs:     make(chan struct{}, max(maxTabs, 1)),
+		browsers: make(map[string]pooledBrowser),
+	}
+}
+
+// Render is ScrapePageHTMLWithChrome in a new tab of the pool's browser, waiting for a free tab slot first
+func (pool *ChromePool) Render(ctx context.Context, pageURL string, chrome ChromeOptions) (string, error) {
+	select {
+	case pool.tabs <- struct{}{}: // Claim a tab slot
+	case <-ctx.Done(): // Cancelled while waiting
+		return "", ctx.Err()
+	}
+	defer func() { <-pool.tabs }() // Free the slot for the next page
+
+	pool.logger().Info("scraping page", "url", pageURL, "pooled", true) // Log scraping action
+	started := time.Now()                                               // For reporting how long a timed-out render ran
+
+	browserCtx, err := pool.browser(chrome) // Launch or reuse the browser for this page's proxy
+	if err != nil {
+		return "", err
+	}
+	tabCtx, cancelTab := chromedp.NewContext(browserCtx) // New tab in the shared browser
+	defer cancelTab()                                    // Close the tab, leaving the browser running
+	renderCtx, cancelRender := context.WithTimeout(tabCtx, chrome.Timeout)
+	defer cancelRender()
+	stopWatching := context.AfterFunc(ctx, cancelRender) // The caller's cancellation aborts this render
+	defer stopWatching()
+
+	return renderPage(renderCtx, pageURL, chrome, started, pool.logger())
+}
+
+// browser returns the running browser for chrome's proxy, launching it on first use
+func (pool *ChromePool) browser(chrome ChromeOptions) (context.Context, error) {
+	key := "" // Direct connection
+	if chrome.Proxy != nil {
+		key = chrome.Proxy.Scheme + "://" + chrome.Proxy.Host
+	}
+
+	pool.mutex.Lock()                                                            // One launch per proxy, even when tabs ask at the same time
+	defer pool.mutex.Unlock()                                                    // Release the lock when done
+	if running, found := pool.browsers[key]; found && running.ctx.Err() == nil { // Reuse a live browser
+		return running.ctx, nil
+	}
+
+	allocatorCtx, cancelAllocator := newChromeAllocator(pool.parent, chrome, pool.logger()) // Launch or connect, as for a single page
+	browserCtx, cancelBrowser := chromedp.NewContext(allocatorCtx)                          // Browser context that tabs are opened from
+	if err := chromedp.Run(browserCtx); err != nil {                                        // Start the browser now so a failure is reported once
+		cancelBrowser()
+		cancelAllocator()
+		return nil, fmt.Errorf("failed to start Chrome: %w", err)
+	}
+	pool.browsers[key] = pooledBrowser{ctx: browserCtx, cancel: func() {
+		cancelBrowser()
+		cancelAllocator()
+	}}
+	return browserCtx, nil
+}
+
+// Close shuts down every browser the pool started
+func (pool *ChromePool) Close() {
+	pool.mutex.Lock()         // Don't race a launch
+	defer pool.mutex.Unlock() // Release the lock when done
+	for key, running := range pool.browsers {
+		running.cancel()
+		delete(pool.browsers, key)
+	}
+}
+
+// logger returns Logger, or the default logger when none was set
+func (pool *ChromePool) logger() *slog.Logger {
+	if pool.Logger == nil {
+		return slog.Default()
+	}
+	return pool.Logger
+}
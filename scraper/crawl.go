@@ -0,0 +1,92 @@
+package scraper // Recursive crawl of allowed hosts for SDS sub-pages
+
+import (
+	"context" // For cancelling page fetches
+	"fmt"     // For wrapping parse errors
+	"net/url" // For resolving and comparing links
+	"path"    // For inspecting link extensions
+	"strings" // For case-insensitive comparisons
+
+	"github.com/PuerkitoBio/goquery"                                   // HTML document parser based on jQuery-like syntax
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Document extension matching and the skip log level
+)
+
+// crawlablePageExtensions lists path extensions that are worth fetching as HTML pages
+var crawlablePageExtensions = map[string]bool{"": true, ".html": true, ".htm": true, ".php": true, ".asp": true, ".aspx": true}
+
+// Crawl follows links breadth-first from seedURL up to maxDepth and returns every document link found; pages are
+// only fetched from the seed's host and hosts matching allowedHosts (see HostMatches)
+func (scraper *Scraper) Crawl(ctx context.Context, seedURL string, maxDepth int, allowedHosts []string) []string {
+	seed, err := url.Parse(seedURL) // Parse the seed to learn its host
+	if err != nil {                 // Nothing to crawl from
+		scraper.logger().Error("invalid crawl seed", "url", seedURL, "error", err)
+		return nil
+	}
+
+	allowedHosts = append([]string{seed.Hostname()}, allowedHosts...) // The seed's own host is always crawlable
+	visited := map[string]bool{seed.String(): true}                   // Pages already queued
+	currentLevel := []*url.URL{seed}                                  // Pages to fetch at the current depth
+	var pdfLinks []string                                             // PDF links found on any page
+
+	for depth := 0; depth <= maxDepth && len(currentLevel) > 0; depth++ { // Stop at the depth limit or when nothing is left
+		var nextLevel []*url.URL // Pages discovered at this depth
+		for _, page := range currentLevel {
+			if ctx.Err() != nil { // Stop crawling once cancelled
+				return pdfLinks
+			}
+			if !scraper.RobotsAllowed(ctx, page.String()) { // Respect robots.txt for every page
+				scraper.logger().Log(ctx, storage.LevelSkip, "robots.txt disallows crawling, skipping", "url", page.String())
+				continue
+			}
+
+			html := string(scraper.GetDataFromURL(ctx, page.String())) // Fetch the page HTML
+			hrefs, err := ExtractLinks(html)
+			if err != nil { // Unparseable page; nothing to follow from it
+				scraper.logger().Error("failed to extract links", "url", page.String(), "error", err)
+				continue
+			}
+			for _, href := range hrefs { // Inspect every link on the page
+				target, err := page.Parse(href) // Resolve the link against the page URL
+				if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+					continue // Skip malformed, mailto:, javascript: and similar links
+				}
+				target.Fragment = "" // Fragments point into the same page
+
+				if storage.HasDocumentExtension(target.String(), scraper.Extensions) { // Documents are leaves
+					pdfLinks = append(pdfLinks, target.String())
+					continue
+				}
+				if !HostAllowed(target.String(), allowedHosts) || visited[target.String()] {
+					continue // Stay on allowed hosts and don't revisit pages
+				}
+				if !crawlablePageExtensions[strings.ToLower(path.Ext(target.Path))] {
+					continue // Skip images, archives and other non-page files
+				}
+				visited[target.String()] = true       // Mark as queued
+				nextLevel = append(nextLevel, target) // Fetch at the next depth
+			}
+		}
+		scraper.logger().Info("crawled depth", "depth", depth, "pages", len(currentLevel), "pdf_links", len(pdfLinks))
+		if depth == maxDepth { // Don't fetch pages beyond the limit
+			break
+		}
+		currentLevel = nextLevel // Descend one level
+	}
+	return pdfLinks
+}
+
+// ExtractLinks returns the href of every <a> tag in the HTML
+func ExtractLinks(html string) ([]string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
+	if err != nil {                                                    // Handle parsing error
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var links []string                                     // Every link target on the page
+	doc.Find("a").Each(func(i int, s *goquery.Selection) { // Iterate over all <a> tags
+		if href, exists := s.Attr("href"); exists {
+			links = append(links, href)
+		}
+	})
+	return links, nil
+}
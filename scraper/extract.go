@@ -0,0 +1,177 @@
+package scraper // Link extraction from scraped HTML
+
+import (
+	"fmt"     // For wrapping errors
+	"net/url" // For parsing and building URLs
+	"regexp"  // For spotting generic link text
+	"strings" // For string manipulation
+
+	"github.com/PuerkitoBio/goquery"                                   // HTML document parser based on jQuery-like syntax
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Document extension matching
+)
+
+// ExtractDocumentLinks parses HTML content and returns all hyperlinks whose path ends in one of exts, e.g. ".pdf"
+func ExtractDocumentLinks(html string, exts []string) ([]string, error) {
+	var documentLinks []string // Slice to store document links
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
+	if err != nil {                                                    // Handle parsing error
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	doc.Find("a").Each(func(i int, s *goquery.Selection) { // Iterate over all <a> tags
+		if href, exists := s.Attr("href"); exists && storage.HasDocumentExtension(href, exts) { // Check the href path's extension, ignoring query and fragment
+			documentLinks = append(documentLinks, href) // Add the document link to the slice
+		}
+	})
+
+	return documentLinks, nil // Return the slice of document links
+}
+
+// genericLinkText matches anchor text that names the file type rather than the product
+var genericLinkText = regexp.MustCompile(`(?i)^(sds|msds|pdf|download|view|open|here|click here|english|spanish|en|es|safety data sheet)$`)
+
+// ExtractDocumentLabels returns the product name, category and revision date around each link
+// ExtractDocumentLinks finds, keyed by the link's href: the anchor text, or its <tr>/<li> row's first
+// cell when the anchor only says "SDS" or similar, the nearest heading or table caption above the row,
+// and the newest date printed in the row
+func ExtractDocumentLabels(html string, exts []string) (map[string]storage.LinkLabel, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
+	if err != nil {                                                    // Handle parsing error
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	labels := make(map[string]storage.LinkLabel) // href -> label
+	doc.Find("a").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		if !exists || !storage.HasDocumentExtension(href, exts) { // Only the links that get downloaded
+			return
+		}
+		if _, seen := labels[href]; seen { // The first occurrence describes it best
+			return
+		}
+		row := s.Closest("tr, li") // The table row or list item holding the link, if any
+		label := storage.LinkLabel{Title: linkTitle(s, row), Category: linkCategory(s, row), RevisionDate: linkRevisionDate(s, row)}
+		if label != (storage.LinkLabel{}) {
+			labels[href] = label
+		}
+	})
+	return labels, nil
+}
+
+// linkTitle returns anchor's text or title attribute, falling back to row's first cell when that says nothing
+func linkTitle(anchor, row *goquery.Selection) string {
+	title := collapseSpace(anchor.Text()) // Visible link text
+	if title == "" {                      // Icon-only links
+		title = collapseSpace(anchor.AttrOr("title", ""))
+	}
+	if (title == "" || genericLinkText.MatchString(title)) && row.Length() > 0 { // "SDS" next to the product name
+		if goquery.NodeName(row) == "li" { // The item's text around its links
+			item := row.Clone()
+			item.Find("a").Remove()
+			if text := collapseSpace(item.Text()); text != "" {
+				return text
+			}
+		} else if cell := row.Children().First(); cell.Length() > 0 && cell.Find("a").Length() == 0 {
+			return collapseSpace(cell.Text())
+		}
+	}
+	return title
+}
+
+// linkCategory returns the caption of the link's table or the nearest heading before the link's
+// row or any of its containers
+func linkCategory(anchor, row *goquery.Selection) string {
+	if caption := collapseSpace(anchor.Closest("table").ChildrenFiltered("caption").First().Text()); caption != "" {
+		return caption
+	}
+	node := anchor // Start from the row when there is one
+	if row.Length() > 0 {
+		node = row
+	}
+	for ; node.Length() > 0; node = node.Parent() { // Walk out until a heading precedes a container
+		if heading := node.PrevAllFiltered("h1, h2, h3, h4, h5, h6").First(); heading.Length() > 0 {
+			return collapseSpace(heading.Text())
+		}
+	}
+	return ""
+}
+
+// collapseSpace trims text and turns each run of whitespace into one space
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ExtractDownloadHandlerLinks returns hrefs that look like download handlers rather than direct links
+// to documents with one of exts
+func ExtractDownloadHandlerLinks(html string, exts []string) ([]string, error) {
+	var handlerLinks []string // Slice to store candidate handler links
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
+	if err != nil {                                                    // Handle parsing error
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	doc.Find("a").Each(func(i int, s *goquery.Selection) { // Iterate over all <a> tags
+		href, exists := s.Attr("href") // Read the link target
+		if !exists {                   // Skip anchors without a target
+			return
+		}
+		if storage.HasDocumentExtension(href, exts) { // Direct document links are handled by ExtractDocumentLinks
+			return
+		}
+		parsedHref, err := url.Parse(strings.ToLower(href)) // Inspect only the path, case-insensitively
+		if err != nil {                                     // Skip malformed links
+			return
+		}
+		if strings.Contains(parsedHref.Path, "download") || strings.Contains(parsedHref.Path, "/sds/") { // Looks like a handler
+			handlerLinks = append(handlerLinks, href)
+		}
+	})
+
+	return handlerLinks, nil // Return the candidate handler links
+}
+
+// metaRefreshTarget returns the URL of the page's <meta http-equiv="refresh" content="0; url=...">, or ""
+// when it has none
+func metaRefreshTarget(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)) // Parse HTML using goquery
+	if err != nil {                                                    // Unparseable pages have no refresh to follow
+		return ""
+	}
+	var target string // First refresh URL found
+	doc.Find("meta[http-equiv]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if !strings.EqualFold(strings.TrimSpace(s.AttrOr("http-equiv", "")), "refresh") { // Some other pragma
+			return true
+		}
+		_, after, found := strings.Cut(s.AttrOr("content", ""), ";") // "5; url=x" -> " url=x"; a bare delay reloads the same page
+		if !found {
+			_, after, found = strings.Cut(s.AttrOr("content", ""), ",") // Older pages separate with a comma
+		}
+		if !found {
+			return true
+		}
+		after = strings.TrimSpace(after)
+		if len(after) >= 3 && strings.EqualFold(after[:3], "url") { // The url= prefix is optional
+			after = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(after[3:]), "="))
+		}
+		target = strings.Trim(after, `'" `) // Targets are sometimes quoted
+		return target == ""                 // Stop at the first usable target
+	})
+	return target
+}
+
+// AbsolutizeLink resolves link against baseURL the way a browser would, so protocol-relative
+// ("//cdn/x.pdf") and path-relative ("../x.pdf", "sub/x.pdf") links work; absolute links pass through.
+// A link or base URL that doesn't parse returns the link unchanged along with the error.
+func AbsolutizeLink(link, baseURL string) (string, error) {
+	reference, err := url.Parse(strings.TrimSpace(link)) // Parse the link as a URL reference
+	if err != nil {
+		return link, err
+	}
+	base, err := url.Parse(baseURL) // BaseURL was validated, but guard anyway
+	if err != nil {
+		return link, fmt.Errorf("invalid base URL: %w", err)
+	}
+	return base.ResolveReference(reference).String(), nil // RFC 3986 resolution
+}
@@ -0,0 +1,55 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"
+)
+
+func TestExtractDocumentLinks(t *testing.T) {
+	tests := []struct {
+		href string
+		want bool
+	}{
+		{"foo.pdf?x=1", true},
+		{"FOO.PDF", true},
+		{"bar.pdf#frag", true},
+		{"/sds/a.pdf", true},
+		{"/sds/a.pdf.html", false},
+		{"/sds/?file=a.pdf", false}, // Only the path's extension counts
+		{"/sds/index.html", false},
+	}
+	for _, test := range tests {
+		links, err := ExtractDocumentLinks(`<html><body><a href="`+test.href+`">SDS</a></body></html>`, storage.DefaultExtensions)
+		if err != nil {
+			t.Fatalf("ExtractDocumentLinks(%q): %v", test.href, err)
+		}
+		if got := len(links) == 1 && links[0] == test.href; got != test.want {
+			t.Errorf("ExtractDocumentLinks(<a href=%q>) = %q, want the link found: %v", test.href, links, test.want)
+		}
+	}
+}
+
+func TestAbsolutizeLink(t *testing.T) {
+	const baseURL = "https://www.duragloss.com/sds/index.html"
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"//cdn/x.pdf", "https://cdn/x.pdf"},
+		{"../x.pdf", "https://www.duragloss.com/x.pdf"},
+		{"sub/x.pdf", "https://www.duragloss.com/sds/sub/x.pdf"},
+		{"/x.pdf", "https://www.duragloss.com/x.pdf"},
+		{"https://other.example/x.pdf", "https://other.example/x.pdf"},
+	}
+	for _, test := range tests {
+		got, err := AbsolutizeLink(test.link, baseURL)
+		if err != nil {
+			t.Errorf("AbsolutizeLink(%q): %v", test.link, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("AbsolutizeLink(%q) = %q, want %q", test.link, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package scraper // Plain HTTP fetches of pages and download handlers
+
+import (
+	"context"  // For managing deadlines, cancellation signals, etc.
+	"errors"   // For spotting Chrome timeouts
+	"fmt"      // For formatted I/O
+	"net/http" // For HTTP client functionality
+	"strings"  // For content type checks
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Content type matching
+)
+
+// GetDataFromURL performs a GET request and returns the response body as bytes, or nil when the request
+// fails or the server answers with anything but a 2xx status, so error pages are never treated as content
+func (scraper *Scraper) GetDataFromURL(ctx context.Context, uri string) []byte {
+	body, err := scraper.fetchPage(ctx, uri) // Rejects 4xx and 5xx answers
+	if err != nil {
+		scraper.logger().Error("request failed", "url", uri, "error", err)
+		return nil
+	}
+	return body
+}
+
+// maxMetaRefreshHops bounds how many <meta http-equiv="refresh"> interstitials ResolveFinalDocumentURL follows
+const maxMetaRefreshHops = 5
+
+// ResolveFinalDocumentURL follows redirects with a HEAD request and returns the final URL if it serves
+// one of the scraper's ContentTypes; an HTML interstitial with a meta refresh is followed to its target,
+// up to maxMetaRefreshHops times
+func (scraper *Scraper) ResolveFinalDocumentURL(ctx context.Context, link string) (string, error) {
+	currentURL := link               // URL being resolved on this hop
+	visited := make(map[string]bool) // Interstitials already seen, to stop refresh loops early
+	for hop := 0; ; hop++ {
+		finalURL, contentType, err := scraper.resolveRedirects(ctx, currentURL) // Follow HTTP redirects
+		if err != nil {
+			return "", err
+		}
+		if storage.ContentTypeAllowed(contentType, scraper.ContentTypes) { // Ends at a document
+			scraper.logger().Info("resolved download link", "url", link, "final_url", finalURL) // Log the resolution
+			return finalURL, nil
+		}
+		if !strings.Contains(contentType, "text/html") { // Only HTML pages can carry a meta refresh
+			return "", fmt.Errorf("%s does not resolve to an accepted document (content type %s)", link, contentType)
+		}
+		if visited[finalURL] { // The interstitials bounce between each other
+			return "", fmt.Errorf("%s loops through meta refresh at %s", link, finalURL)
+		}
+		if hop == maxMetaRefreshHops { // An endless chain
+			return "", fmt.Errorf("%s does not resolve to a document within %d meta refresh hops", link, maxMetaRefreshHops)
+		}
+		visited[finalURL] = true
+
+		pageHTML, err := scraper.fetchPage(ctx, finalURL) // Read the interstitial
+		if err != nil {
+			return "", err
+		}
+		target := metaRefreshTarget(string(pageHTML)) // Where the page bounces to, if anywhere
+		if target == "" {                             // A plain page, not an interstitial
+			return "", fmt.Errorf("%s does not resolve to an accepted document (content type %s)", link, contentType)
+		}
+		nextURL, err := AbsolutizeLink(target, finalURL) // Refresh targets are relative to the interstitial
+		if err != nil {
+			return "", fmt.Errorf("invalid meta refresh target %q on %s: %w", target, finalURL, err)
+		}
+		scraper.logger().Debug("following meta refresh", "url", finalURL, "target", nextURL)
+		currentURL = nextURL
+	}
+}
+
+// resolveRedirects sends a HEAD request for link and returns the URL and content type of the final hop
+func (scraper *Scraper) resolveRedirects(ctx context.Context, link string) (string, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil) // Build a cancellable HEAD request
+	if err != nil {                                                             // Handle request construction error
+		return "", "", fmt.Errorf("failed to build request for %s: %w", link, err)
+	}
+
+	resp, err := scraper.HTTPClient.Do(request) // Send the HEAD request; the default redirect policy follows up to 10 hops
+	if err != nil {                             // Handle request error
+		return "", "", fmt.Errorf("failed to resolve %s: %w", link, err)
+	}
+	resp.Body.Close() // HEAD responses have no body worth reading
+
+	if resp.StatusCode != http.StatusOK { // The final hop must succeed
+		return "", "", fmt.Errorf("failed to resolve %s: %s", link, resp.Status)
+	}
+	return resp.Request.URL.String(), resp.Header.Get("Content-Type"), nil // URL after all redirects
+}
+
+// ScrapePageHTML returns the page's HTML, rendered with headless Chrome when useChrome is set and
+// fetched with a plain GET otherwise or when Chrome can't run; static pages work either way. A
+// Chrome timeout is returned as an error wrapping ErrChromeTimeout rather than falling back, so the
+// caller decides whether a page that slow is worth a plain fetch.
+func (scraper *Scraper) ScrapePageHTML(ctx context.Context, pageURL string, chrome ChromeOptions, useChrome bool) (string, error) {
+	if useChrome { // Render JavaScript-built content first
+		render := scraper.ScrapePageHTMLWithChrome // A fresh browser for this page
+		if scraper.ChromePool != nil {             // Open a tab in the shared browser instead
+			render = scraper.ChromePool.Render
+		}
+		pageHTML, err := render(ctx, pageURL, chrome)
+		switch {
+		case errors.Is(err, ErrChromeTimeout): // Let the caller choose what a timeout means
+			return "", err
+		case err != nil: // Chrome missing or crashed
+			scraper.logger().Warn("Chrome scrape failed, falling back to plain HTTP", "url", pageURL, "error", err)
+		case pageHTML == "": // Chrome navigated but captured nothing
+			scraper.logger().Warn("Chrome rendered an empty page, falling back to plain HTTP", "url", pageURL)
+		default:
+			scraper.logger().Info("scraped page with Chrome", "url", pageURL, "bytes", len(pageHTML))
+			return pageHTML, nil
+		}
+	}
+
+	body, err := scraper.fetchPage(ctx, pageURL) // Lightweight fetch without JavaScript
+	if err != nil {
+		return "", err
+	}
+	scraper.logger().Info("fetched page without Chrome", "url", pageURL, "bytes", len(body))
+	return string(body), nil
+}
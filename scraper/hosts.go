@@ -0,0 +1,34 @@
+package scraper // Host allowlist for PDF links and crawled pages
+
+import (
+	"net/url" // For extracting link hosts
+	"strings" // For case folding and suffix checks
+)
+
+// HostMatches reports whether host matches pattern: "*" matches every host, "*.example.com" matches
+// example.com and all of its subdomains, and anything else must equal host exactly (ignoring case)
+func HostMatches(host, pattern string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))       // Hosts are case-insensitive; ignore a fully qualified dot
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, ".")) // Patterns too
+	if pattern == "*" {                                         // Everything allowed
+		return true
+	}
+	if domain, isWildcard := strings.CutPrefix(pattern, "*."); isWildcard { // The domain and any subdomain
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
+
+// HostAllowed reports whether link's host matches any of patterns
+func HostAllowed(link string, patterns []string) bool {
+	parsed, err := url.Parse(link) // Parse the link to find its host
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if HostMatches(parsed.Hostname(), pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package scraper // Library entry point for finding document links on a page
+
+import (
+	"context"  // For cancelling the page fetch
+	"errors"   // For the robots.txt sentinel
+	"fmt"      // For wrapping errors
+	"io"       // For reading the page body
+	"net/http" // For fetching the page
+)
+
+// ErrRobotsDisallowed reports that robots.txt forbids fetching the page
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// Links fetches pageURL with http.DefaultClient and returns its document links; see Scraper.Links
+func Links(ctx context.Context, pageURL string) ([]string, error) {
+	return New(http.DefaultClient, "", false).Links(ctx, pageURL)
+}
+
+// Links fetches pageURL with a plain GET, without Chrome, and returns the links to documents with
+// one of Extensions, resolved against pageURL and normalized, in page order without duplicates.
+// It returns an error wrapping ErrRobotsDisallowed when robots.txt forbids the page, and an error
+// for a failed request or a non-2xx answer.
+func (scraper *Scraper) Links(ctx context.Context, pageURL string) ([]string, error) {
+	if !scraper.RobotsAllowed(ctx, pageURL) { // Same politeness as the command
+		return nil, fmt.Errorf("%w: %s", ErrRobotsDisallowed, pageURL)
+	}
+	pageHTML, err := scraper.fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	hrefs, err := ExtractDocumentLinks(string(pageHTML), scraper.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract links from %s: %w", pageURL, err)
+	}
+
+	var links []string            // Absolute links in page order
+	seen := make(map[string]bool) // Links already returned
+	for _, href := range hrefs {
+		link, err := AbsolutizeLink(NormalizeURL(href, pageURL), pageURL)
+		if err != nil || seen[link] { // Unusable href or a repeat
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// fetchPage GETs pageURL and returns its body, treating anything but a 2xx answer as an error
+func (scraper *Scraper) fetchPage(ctx context.Context, pageURL string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil) // Build a cancellable GET request
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %s: %w", pageURL, err)
+	}
+	response, err := scraper.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 { // Error pages have no useful links
+		return nil, fmt.Errorf("failed to fetch %s: %s", pageURL, response.Status)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	return body, nil
+}
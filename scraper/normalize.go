@@ -0,0 +1,76 @@
+package scraper // Canonical URL form used for deduplicating links
+
+import (
+	"net"     // For joining hosts and ports
+	"net/url" // For parsing and rebuilding URLs
+	"slices"  // For sorting query parameters
+	"strings" // For case folding and trimming
+)
+
+// defaultPorts maps each scheme to the port that can be dropped from its URLs
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeURL returns the canonical form of raw so equivalent links compare equal: relative paths are
+// resolved against baseURL, the scheme and host are lowercased, query parameters are sorted by name, and
+// default ports, fragments and trailing slashes are dropped. The escaping of the path and of each query
+// parameter is kept as written, since servers may treat "%20" and "+" differently. Unparseable input is
+// returned trimmed but unchanged.
+func NormalizeURL(raw, baseURL string) string {
+	raw = strings.TrimSpace(raw)  // Ignore stray whitespace from the HTML
+	parsed, err := url.Parse(raw) // Parse the link
+	if err != nil {               // Leave malformed links for later stages to report
+		return raw
+	}
+
+	if base, err := url.Parse(baseURL); err == nil && base.Host != "" { // Resolve relative links and dot segments
+		parsed = base.ResolveReference(parsed)
+	}
+	if parsed.Host == "" { // Still relative; nothing more can be canonicalized reliably
+		return parsed.String()
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme) // Schemes are case-insensitive
+	hostname := strings.ToLower(parsed.Hostname()) // Hosts are case-insensitive
+	port := parsed.Port()                          // Explicit port, if any
+	switch {
+	case port != "" && port != defaultPorts[parsed.Scheme]: // Keep non-default ports
+		parsed.Host = net.JoinHostPort(hostname, port)
+	case strings.Contains(hostname, ":"): // Keep IPv6 literals bracketed
+		parsed.Host = "[" + hostname + "]"
+	default: // Drop the default port
+		parsed.Host = hostname
+	}
+
+	if len(parsed.Path) > 1 { // "/a.pdf/" and "/a.pdf" name the same resource
+		parsed.Path = strings.TrimRight(parsed.Path, "/")
+		parsed.RawPath = strings.TrimRight(parsed.RawPath, "/") // Keep the original escaping, if it differs
+	}
+	parsed.Fragment = "" // Fragments never reach the server
+	parsed.RawFragment = ""
+	parsed.ForceQuery = false // Drop a bare trailing "?"
+	parsed.RawQuery = sortQuery(parsed.RawQuery)
+	return parsed.String()
+}
+
+// sortQuery orders the parameters of rawQuery by name, keeping each one's escaping and the relative
+// order of repeated names. A query whose names don't unescape cleanly is returned unchanged.
+func sortQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	params := strings.Split(rawQuery, "&") // Each "name=value" pair as written
+	names := make(map[string]string, len(params))
+	for _, param := range params {
+		rawName, _, _ := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(rawName)
+		if err != nil { // Malformed escaping; leave the query alone
+			return rawQuery
+		}
+		names[param] = name
+	}
+	slices.SortStableFunc(params, func(a, b string) int { return strings.Compare(names[a], names[b]) })
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,26 @@
+package scraper
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"HTTPS://WWW.Duragloss.COM:443/sds/a.pdf", "https://www.duragloss.com/sds/a.pdf"},
+		{"https://www.duragloss.com/sds/a.pdf/", "https://www.duragloss.com/sds/a.pdf"},
+		{"https://www.duragloss.com/sds/a.pdf#page=2", "https://www.duragloss.com/sds/a.pdf"},
+		{"/sds/a.pdf", "https://www.duragloss.com/sds/a.pdf"},
+		{"https://www.duragloss.com/sds/a.pdf?b=2&a=1", "https://www.duragloss.com/sds/a.pdf?a=1&b=2"},         // Parameters are sorted
+		{"https://www.duragloss.com/sds/a.pdf?b=1&a=x&b=0", "https://www.duragloss.com/sds/a.pdf?a=x&b=1&b=0"}, // Repeats keep their order
+		{"https://www.duragloss.com/sds/a.pdf?v=1&q=a%20b", "https://www.duragloss.com/sds/a.pdf?q=a%20b&v=1"}, // and their escaping
+		{"https://www.duragloss.com/sds/a.pdf?b=1&%zz=2", "https://www.duragloss.com/sds/a.pdf?b=1&%zz=2"},     // Malformed queries stay as written
+		{"https://www.duragloss.com/sds/a%2Fb.pdf/", "https://www.duragloss.com/sds/a%2Fb.pdf"},                // and the path's
+		{"https://www.duragloss.com:8443/sds/a.pdf", "https://www.duragloss.com:8443/sds/a.pdf"},               // Non-default ports stay
+	}
+	for _, test := range tests {
+		if got := NormalizeURL(test.raw, "https://www.duragloss.com/"); got != test.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", test.raw, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scraper // Revision dates printed next to document links
+
+import (
+	"context" // For logging skipped links at the skip level
+	"regexp"  // For spotting dates in the page text
+	"strconv" // For parsing date numbers
+	"strings" // For month names and label keys
+	"time"    // For validating and comparing dates
+
+	"github.com/PuerkitoBio/goquery"                                   // HTML document parser based on jQuery-like syntax
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link labels
+)
+
+// RevisionLayout is how revision dates are stored in labels and manifest records
+const RevisionLayout = "2006-01-02"
+
+// monthNames matches English month names and their usual abbreviations
+const monthNames = `jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sept?(?:ember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?`
+
+// datePatterns are the date formats SDS pages print, with named year, month and day groups; a
+// missing day means the first of the month
+var datePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?P<year>\d{4})[-/.](?P<month>\d{1,2})[-/.](?P<day>\d{1,2})\b`),                                      // 2024-03-15, 2024/3/15
+	regexp.MustCompile(`\b(?P<month>\d{1,2})[-/](?P<day>\d{1,2})[-/](?P<year>\d{4}|\d{2})\b`),                                  // 03/15/2024, 3-15-24
+	regexp.MustCompile(`(?i)\b(?P<month>` + monthNames + `)\.?\s+(?P<day>\d{1,2})(?:st|nd|rd|th)?,?\s+(?P<year>\d{4})\b`),      // March 15, 2024
+	regexp.MustCompile(`(?i)\b(?P<day>\d{1,2})(?:st|nd|rd|th)?[\s-]+(?P<month>` + monthNames + `)\.?[\s,-]+(?P<year>\d{4})\b`), // 15 March 2024, 15-Mar-2024
+	regexp.MustCompile(`(?i)\b(?P<month>` + monthNames + `)\.?,?[\s-]+(?P<year>\d{4})\b`),                                      // March 2024
+	regexp.MustCompile(`\b(?P<month>\d{1,2})/(?P<year>\d{4})\b`),                                                               // 03/2024
+}
+
+// RevisionDate returns the newest date found in text, in any of datePatterns, formatted with
+// RevisionLayout; it returns "" when text has no plausible date
+func RevisionDate(text string) string {
+	var newest time.Time
+	for _, pattern := range datePatterns {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			if date, ok := matchDate(pattern, match); ok && date.After(newest) {
+				newest = date
+			}
+		}
+	}
+	if newest.IsZero() {
+		return ""
+	}
+	return newest.Format(RevisionLayout)
+}
+
+// matchDate turns one datePatterns match into a date, rejecting impossible ones like February 30.
+// Numeric dates are read month first, as on US pages, unless the month is over 12.
+func matchDate(pattern *regexp.Regexp, match []string) (time.Time, bool) {
+	year, month, day := 0, 0, 1 // No day means the first of the month
+	hasDay := false             // Only a full date can be day first
+	for index, name := range pattern.SubexpNames() {
+		value := strings.ToLower(match[index])
+		switch name {
+		case "year":
+			year, _ = strconv.Atoi(value)
+			if len(value) == 2 { // Two-digit years the way time.Parse reads them
+				year += 1900
+				if year < 1969 {
+					year += 100
+				}
+			}
+		case "month":
+			if number, err := strconv.Atoi(value); err == nil {
+				month = number
+			} else { // Month name; the first three letters identify it
+				month = strings.Index("janfebmaraprmayjunjulaugsepoctnovdec", value[:3])/3 + 1
+			}
+		case "day":
+			day, _ = strconv.Atoi(value)
+			hasDay = true
+		}
+	}
+	if hasDay && month > 12 && day <= 12 { // 15/03/2024 is day first
+		month, day = day, month
+	}
+	if year < 1970 || year > 2100 || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return date, date.Day() == day // Normalized means out of range, e.g. 04/31
+}
+
+// linkRevisionDate returns the newest date in the link's row, or, outside tables and lists, in its
+// parent when that holds no other link, so one product's date isn't given to its neighbours
+func linkRevisionDate(anchor, row *goquery.Selection) string {
+	scope := row
+	if row.Length() == 0 {
+		scope = anchor
+		if parent := anchor.Parent(); parent.Find("a").Length() == 1 {
+			scope = parent
+		}
+	}
+	var texts []string // Every text node on its own, so "Product" and "03/15/2024" in adjacent cells don't merge
+	scope.Find("*").AddSelection(scope).Contents().Each(func(i int, node *goquery.Selection) {
+		if goquery.NodeName(node) == "#text" {
+			texts = append(texts, node.Text())
+		}
+	})
+	return RevisionDate(strings.Join(append(texts, anchor.AttrOr("title", "")), " "))
+}
+
+// NewestRevisions drops the links whose label names the same product and category as another link
+// with a newer revision date, so a page listing both the old and the current sheet yields only the
+// current one. Links without a date, or titled only "SDS" or similar, are always kept. labels are keyed
+// by canonical link, as NormalizeURL and AbsolutizeLink against baseURL produce it.
+func (scraper *Scraper) NewestRevisions(ctx context.Context, links []string, labels map[string]storage.LinkLabel, baseURL string) []string {
+	product := func(link string) (storage.LinkLabel, string) { // The label and the product it names
+		canonical, _ := AbsolutizeLink(NormalizeURL(link, baseURL), baseURL) // Unparseable links come back as is, the way they were keyed
+		label := labels[canonical]
+		if label.RevisionDate == "" || label.Title == "" || genericLinkText.MatchString(label.Title) {
+			return label, ""
+		}
+		return label, strings.ToLower(label.Category + "\x00" + label.Title)
+	}
+
+	newest := make(map[string]string) // Product -> newest revision date; YYYY-MM-DD sorts as text
+	for _, link := range links {
+		if label, key := product(link); key != "" && label.RevisionDate > newest[key] {
+			newest[key] = label.RevisionDate
+		}
+	}
+	var kept []string
+	for _, link := range links {
+		if label, key := product(link); key != "" && label.RevisionDate < newest[key] {
+			scraper.logger().Log(ctx, storage.LevelSkip, "older revision of the same product, skipping", "url", link, "title", label.Title, "revision_date", label.RevisionDate, "newest", newest[key])
+			continue
+		}
+		kept = append(kept, link)
+	}
+	return kept
+}
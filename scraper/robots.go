@@ -0,0 +1,201 @@
+package scraper // robots.txt parsing and per-host caching
+
+import (
+	"bufio"    // For reading robots.txt line by line
+	"bytes"    // For wrapping the fetched robots.txt
+	"context"  // For cancelling robots.txt requests
+	"net/http" // For fetching robots.txt
+	"net/url"  // For locating robots.txt on a host
+	"regexp"   // For matching wildcard patterns
+	"strings"  // For directive parsing and matching
+	"sync"     // For guarding the cache
+)
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	pattern string // Path pattern, possibly with * wildcards and a trailing $
+	allow   bool   // True for Allow, false for Disallow
+}
+
+// robotsCache remembers the parsed rules that apply to us for each host
+type robotsCache struct {
+	mutex sync.Mutex              // Guards hosts, but not the fetches
+	hosts map[string]*robotsEntry // scheme://host -> its rules, once fetched
+}
+
+// robotsEntry is one host's robots.txt, fetched once by whichever caller asks first
+type robotsEntry struct {
+	ready chan struct{} // Closed once rules is set
+	rules []robotsRule  // Rules for our user-agent
+}
+
+// RobotsAllowed returns true if robots.txt on the URL's host permits us to fetch it
+func (scraper *Scraper) RobotsAllowed(ctx context.Context, rawURL string) bool {
+	if scraper.IgnoreRobots { // Checks were disabled for local testing
+		return true
+	}
+
+	parsedURL, err := url.Parse(rawURL) // Parse to find the host and path
+	if err != nil || parsedURL.Host == "" {
+		return true // Nothing to check against; let the request report the problem
+	}
+
+	rules := scraper.robots.rulesFor(ctx, parsedURL, scraper.fetchRobotsRules) // Cached rules for this host
+	path := parsedURL.EscapedPath()                                            // Rules are matched against the escaped path
+	if path == "" {
+		path = "/"
+	}
+	if parsedURL.RawQuery != "" { // Query strings are part of the matched path
+		path += "?" + parsedURL.RawQuery
+	}
+	return robotsPathAllowed(rules, path)
+}
+
+// rulesFor returns the cached rules for the URL's host, fetching robots.txt with fetch on first use;
+// callers for the same host wait for that fetch, while other hosts aren't held up by it. A result that
+// fetch calls inconclusive, or one from a fetch ctx cut short, is handed to the waiters but not cached,
+// so the next caller tries again.
+func (cache *robotsCache) rulesFor(ctx context.Context, target *url.URL, fetch func(context.Context, string) ([]robotsRule, bool)) []robotsRule {
+	hostKey := target.Scheme + "://" + target.Host // Robots rules are scoped to scheme and host
+
+	cache.mutex.Lock()
+	entry, found := cache.hosts[hostKey]
+	if !found { // First request for this host; this caller fetches
+		entry = &robotsEntry{ready: make(chan struct{})}
+		cache.hosts[hostKey] = entry
+	}
+	cache.mutex.Unlock()
+
+	if !found {
+		defer close(entry.ready) // Release the waiters however the fetch ends
+		rules, definitive := fetch(ctx, hostKey+"/robots.txt")
+		entry.rules = rules
+		if !definitive || ctx.Err() != nil { // Don't let one failed or cancelled fetch allow everything for the whole run
+			cache.mutex.Lock()
+			delete(cache.hosts, hostKey)
+			cache.mutex.Unlock()
+		}
+		return entry.rules
+	}
+	select {
+	case <-entry.ready: // Fetched, by us earlier or by another worker just now
+		return entry.rules
+	case <-ctx.Done(): // Cancelled while waiting; the request itself will fail
+		return nil
+	}
+}
+
+// fetchRobotsRules downloads robots.txt and returns the rules for our user-agent, allowing everything on failure.
+// The bool is true when the answer is definitive: the file was read, or the server said it doesn't exist with a 4xx.
+func (scraper *Scraper) fetchRobotsRules(ctx context.Context, robotsURL string) ([]robotsRule, bool) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil) // Build a cancellable GET request
+	if err != nil {                                                                 // Handle request construction error
+		scraper.logger().Warn("cannot build robots.txt request, allowing all", "url", robotsURL, "error", err)
+		return nil, true // Retrying would build the same bad request
+	}
+
+	resp, err := scraper.HTTPClient.Do(request) // Fetch robots.txt, identifying ourselves the same way as for downloads
+	if err != nil {                             // Unreachable robots.txt: default to allowing
+		scraper.logger().Warn("robots.txt unreachable, allowing all", "url", robotsURL, "error", err)
+		return nil, false
+	}
+	defer resp.Body.Close() // Ensure response body is closed
+
+	if resp.StatusCode != http.StatusOK { // Missing or broken robots.txt: default to allowing
+		scraper.logger().Warn("robots.txt unavailable, allowing all", "url", robotsURL, "status", resp.StatusCode)
+		return nil, resp.StatusCode >= 400 && resp.StatusCode < 500 // A 4xx means there is no robots.txt; a 5xx may clear up
+	}
+
+	var content bytes.Buffer                               // Raw robots.txt content
+	if _, err := content.ReadFrom(resp.Body); err != nil { // Read the whole file
+		scraper.logger().Warn("failed to read robots.txt, allowing all", "url", robotsURL, "error", err)
+		return nil, false
+	}
+	return parseRobotsRules(content.String(), scraper.UserAgent), true
+}
+
+// parseRobotsRules extracts the rules from the group matching userAgent, falling back to the * group
+func parseRobotsRules(content, userAgent string) []robotsRule {
+	lowerAgent := strings.ToLower(userAgent) // Agent names match case-insensitively
+
+	var specificRules, wildcardRules []robotsRule // Rules for a named group matching us, and for *
+	var groupAgents []string                      // User-agents of the group being read
+	inRules := false                              // True once the current group has started listing rules
+	foundSpecific := false                        // True if any named group matched us
+
+	scanner := bufio.NewScanner(strings.NewReader(content)) // Read robots.txt line by line
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#") // Drop comments
+		field, value, found := strings.Cut(line, ":")  // Split directive and value
+		if !found {                                    // Not a directive
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field)) // Directives are case-insensitive
+		value = strings.TrimSpace(value)                  // Values may be padded
+
+		switch field {
+		case "user-agent":
+			if inRules { // A user-agent after rules starts a new group
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" { // An empty Disallow allows everything
+				continue
+			}
+			rule := robotsRule{pattern: value, allow: field == "allow"} // Rule for the current group
+			for _, agent := range groupAgents {
+				if agent == "*" {
+					wildcardRules = append(wildcardRules, rule)
+				} else if strings.Contains(lowerAgent, agent) {
+					specificRules = append(specificRules, rule)
+					foundSpecific = true
+				}
+			}
+		}
+	}
+
+	if foundSpecific { // A group naming us overrides the * group
+		return specificRules
+	}
+	return wildcardRules
+}
+
+// robotsPathAllowed applies the longest matching rule, with Allow winning ties
+func robotsPathAllowed(rules []robotsRule, path string) bool {
+	allowed := true    // Everything is allowed unless a rule says otherwise
+	longestMatch := -1 // Length of the most specific matching pattern so far
+	for _, rule := range rules {
+		if !robotsPatternMatches(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longestMatch || (len(rule.pattern) == longestMatch && rule.allow) {
+			longestMatch = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsPatternMatches reports whether path matches a robots.txt pattern with * and $ support
+func robotsPatternMatches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$") // $ pins the pattern to the end of the path
+	pattern = strings.TrimSuffix(pattern, "$")  // Drop the anchor before quoting
+
+	pieces := strings.Split(pattern, "*") // Literal pieces separated by wildcards
+	for index, piece := range pieces {    // Quote each literal so only * is special
+		pieces[index] = regexp.QuoteMeta(piece)
+	}
+	expression := "^" + strings.Join(pieces, ".*") // Patterns always match from the start of the path
+	if anchored {
+		expression += "$"
+	}
+
+	matcher, err := regexp.Compile(expression) // Compile the translated pattern
+	if err != nil {                            // Quoted patterns always compile, but be safe
+		return false
+	}
+	return matcher.MatchString(path)
+}
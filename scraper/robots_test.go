@@ -0,0 +1,50 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestRulesForCachesOnlyDefinitiveFetches(t *testing.T) {
+	disallowAll := []robotsRule{{pattern: "/", allow: false}}
+	tests := []struct {
+		name       string
+		definitive bool
+		cancel     bool // Cancel the first caller's context during its fetch
+		wantCached bool
+	}{
+		{"completed fetch is cached", true, false, true},
+		{"inconclusive fetch is retried", false, false, false},
+		{"cancelled fetch is retried", true, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := &robotsCache{hosts: make(map[string]*robotsEntry)}
+			target, _ := url.Parse("https://www.duragloss.com/sds/a.pdf")
+			fetches := 0
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cache.rulesFor(ctx, target, func(context.Context, string) ([]robotsRule, bool) {
+				fetches++
+				if test.cancel {
+					cancel()
+					return nil, test.definitive
+				}
+				return disallowAll, test.definitive
+			})
+
+			rules := cache.rulesFor(context.Background(), target, func(context.Context, string) ([]robotsRule, bool) {
+				fetches++
+				return disallowAll, true
+			})
+			if wantFetches := map[bool]int{true: 1, false: 2}[test.wantCached]; fetches != wantFetches {
+				t.Errorf("robots.txt fetched %d times, want %d", fetches, wantFetches)
+			}
+			if robotsPathAllowed(rules, "/sds/a.pdf") {
+				t.Errorf("second caller got rules %v that allow everything, want the disallow", rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package scraper discovers SDS PDF links: it renders the index page with headless Chrome,
+// extracts links from HTML, reads sitemaps, crawls sub-pages, and honours robots.txt.
+// Links returns one page's document links for programs that embed it.
+package scraper // Page scraping and link discovery
+
+import (
+	"log/slog" // For the optional logger
+	"net/http" // For the HTTP client used to fetch pages
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Default document types
+)
+
+// Scraper fetches pages, sitemaps, and robots.txt over a shared HTTP client
+type Scraper struct {
+	HTTPClient   *http.Client // Client for every plain HTTP fetch
+	UserAgent    string       // User-Agent matched against robots.txt groups
+	IgnoreRobots bool         // Skip every robots.txt check (for local testing)
+	ChromePool   *ChromePool  // Shared browser for Chrome renders; nil launches a browser per page
+	Extensions   []string     // Link path extensions that count as documents, e.g. ".pdf"
+	ContentTypes []string     // Media types a download handler must resolve to
+	Logger       *slog.Logger // Destination for the Scraper's logs; nil uses slog.Default()
+
+	robots *robotsCache // Parsed robots.txt rules per host
+}
+
+// New returns a Scraper that fetches through client and identifies as userAgent in robots.txt matching
+func New(client *http.Client, userAgent string, ignoreRobots bool) *Scraper {
+	return &Scraper{
+		HTTPClient:   client,
+		UserAgent:    userAgent,
+		IgnoreRobots: ignoreRobots,
+		Extensions:   storage.DefaultExtensions,
+		ContentTypes: storage.DefaultContentTypes,
+		robots:       &robotsCache{hosts: make(map[string]*robotsEntry)},
+	}
+}
+
+// logger returns Logger, or the default logger when none was set
+func (scraper *Scraper) logger() *slog.Logger {
+	if scraper.Logger == nil {
+		return slog.Default()
+	}
+	return scraper.Logger
+}
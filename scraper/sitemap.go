@@ -0,0 +1,58 @@
+package scraper // XML sitemap parsing used as a fallback source of document links
+
+import (
+	"context"      // For cancelling sitemap requests
+	"encoding/xml" // For decoding sitemap documents
+	"strings"      // For trimming locations
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Document extension matching
+)
+
+// maxSitemapDepth limits how many nested sitemap indexes are followed
+const maxSitemapDepth = 2
+
+// sitemapDocument covers both <urlset> sitemaps and <sitemapindex> indexes
+type sitemapDocument struct {
+	URLs     []sitemapLocation `xml:"url"`     // Page entries of a <urlset>
+	Sitemaps []sitemapLocation `xml:"sitemap"` // Child sitemaps of a <sitemapindex>
+}
+
+// sitemapLocation is a single <url> or <sitemap> entry
+type sitemapLocation struct {
+	Loc string `xml:"loc"` // Absolute URL of the entry
+}
+
+// ExtractDocumentLinksFromSitemap fetches a sitemap and returns every <loc> that points at a document
+// with one of the scraper's Extensions
+func (scraper *Scraper) ExtractDocumentLinksFromSitemap(ctx context.Context, sitemapURL string) []string {
+	return scraper.collectSitemapDocumentLinks(ctx, sitemapURL, 0) // Start at the top-level sitemap
+}
+
+// collectSitemapDocumentLinks parses one sitemap, descending into child sitemaps up to maxSitemapDepth
+func (scraper *Scraper) collectSitemapDocumentLinks(ctx context.Context, sitemapURL string, depth int) []string {
+	data := scraper.GetDataFromURL(ctx, sitemapURL) // Fetch the sitemap XML
+	if len(data) == 0 {                             // Nothing to parse
+		return nil
+	}
+
+	var document sitemapDocument                           // Parsed sitemap contents
+	if err := xml.Unmarshal(data, &document); err != nil { // Decode the XML
+		scraper.logger().Error("failed to parse sitemap", "url", sitemapURL, "error", err)
+		return nil
+	}
+
+	var documentLinks []string            // Document links found in this sitemap and its children
+	for _, entry := range document.URLs { // Keep page entries that are documents
+		location := strings.TrimSpace(entry.Loc) // Sitemaps often pad <loc> with whitespace
+		if storage.HasDocumentExtension(location, scraper.Extensions) {
+			documentLinks = append(documentLinks, location)
+		}
+	}
+
+	if depth < maxSitemapDepth { // Follow nested sitemap indexes
+		for _, child := range document.Sitemaps {
+			documentLinks = append(documentLinks, scraper.collectSitemapDocumentLinks(ctx, strings.TrimSpace(child.Loc), depth+1)...)
+		}
+	}
+	return documentLinks
+}
@@ -0,0 +1,316 @@
+package main // Scraping each seed index page for PDF links
+
+import (
+	"context"       // For managing deadlines, cancellation signals, etc.
+	"crypto/sha256" // For hashing raw page HTML
+	"encoding/hex"  // For encoding page hashes
+	"errors"        // For spotting Chrome timeouts
+	"log/slog"      // For structured logging
+	"net/url"       // For building cache file names from seed URLs
+	"os"            // For touching reused cached pages
+	"regexp"        // For slugging seed URLs
+	"strings"       // For trimming slugs
+	"sync"          // For waiting on parallel seeds
+	"time"          // For archive date stamps
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/scraper" // Page scraping and link discovery
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage" // Link store, manifest, and file helpers
+)
+
+// defaultHTMLCache is where a single seed's rendered page is cached
+const defaultHTMLCache = "duragloss.html"
+
+// nonSlugCharacters matches the runs of characters replaced in cache file names
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// htmlCachePath returns where seedURL's rendered page is cached: duragloss.html for a lone seed,
+// and a name derived from the URL when there are several so reordering seeds can't mix caches up
+func htmlCachePath(seedURL string, seedCount int) string {
+	if seedCount <= 1 { // Keep the historical file name
+		return defaultHTMLCache
+	}
+	parsed, err := url.Parse(seedURL) // Seeds were validated, but guard anyway
+	if err != nil {
+		return defaultHTMLCache
+	}
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(parsed.Host+parsed.Path), "-") // e.g. www-duragloss-com-sds-sheets
+	return "duragloss-" + strings.Trim(slug, "-") + ".html"
+}
+
+// scrapeSeed loads seedURL's HTML from htmlPath or by scraping it, then returns the PDF links it
+// contains, the PDFs behind its download handlers, and anything found by crawling from it, along with
+// the page's product names and categories keyed like the download links; with pageHashes set, an
+// unchanged raw page reuses the cached render instead of starting Chrome
+func scrapeSeed(ctx context.Context, pageScraper *scraper.Scraper, pageHashes *storage.PageHashIndex, config Config, seedURL, htmlPath string) ([]string, map[string]storage.LinkLabel) {
+	htmlExpired := config.HTMLTTL.Duration > 0 && storage.FileOlderThan(htmlPath, config.HTMLTTL.Duration) // Cached page is past its TTL
+	if htmlExpired {
+		slog.Info("cached HTML expired, re-scraping", "file", htmlPath, "ttl", config.HTMLTTL.Duration)
+	}
+	useCachedHTML := storage.FileExists(htmlPath) && !config.Refresh && !htmlExpired // Reuse the cached page when it's still wanted
+	var pageHash string                                                              // Raw-HTML hash to record once the page is rendered
+	if !useCachedHTML && pageHashes != nil && !config.Refresh && !config.NoChrome {  // A plain GET is cheaper than a Chrome render
+		useCachedHTML, pageHash = pageUnchanged(ctx, pageScraper, pageHashes, seedURL, htmlPath)
+	}
+	var htmlContent string // Page HTML to extract links from
+	if useCachedHTML {     // Read the cached copy
+		cachedHTML, err := storage.ReadAFileAsString(htmlPath)
+		if err != nil { // Unreadable cache; the missing-HTML warning below follows
+			slog.Error("failed to read cached HTML", "error", err)
+		}
+		htmlContent = cachedHTML
+	} else if pageScraper.RobotsAllowed(ctx, seedURL) { // Only scrape pages robots.txt permits
+		htmlContent = scrapeSeedHTML(ctx, pageScraper, config, seedURL)
+		if htmlContent != "" && !config.ListOnly { // A failed scrape keeps the old cache; list-only runs leave the disk untouched
+			replaceCachedHTML(htmlPath, htmlContent, config.ArchiveHTML)
+		}
+		if pageHash != "" && htmlContent != "" && !config.ListOnly { // Remember the raw page this render belongs to
+			if err := pageHashes.Set(seedURL, pageHash); err != nil {
+				slog.Error("failed to save page hash", "url", seedURL, "error", err)
+			}
+		}
+	} else {
+		slog.Warn("robots.txt disallows scraping", "url", seedURL) // Log the refusal
+	}
+
+	var pdfLinks []string                    // PDF links found from this seed
+	labels := map[string]storage.LinkLabel{} // Page context per canonical link
+	if htmlContent != "" {                   // Proceed if there is page HTML
+		var err error
+		pdfLinks, labels, err = pageDocumentLinks(ctx, pageScraper, config, htmlContent) // Extract document links from HTML
+		if err != nil {
+			slog.Error("failed to extract links", "url", seedURL, "error", err)
+		}
+		warnIfSuspiciousPage(seedURL, htmlPath, htmlContent, len(pdfLinks))
+
+		if config.FollowDownloadLinks { // Also chase download handlers that redirect to a PDF
+			handlerLinks, err := scraper.ExtractDownloadHandlerLinks(htmlContent, pageScraper.Extensions)
+			if err != nil {
+				slog.Error("failed to extract download links", "url", seedURL, "error", err)
+			}
+			for _, link := range handlerLinks {
+				absoluteLink, err := scraper.AbsolutizeLink(link, config.BaseURL) // Handlers are often relative
+				if err != nil {
+					slog.Warn("skipping download link", "url", link, "error", err)
+					continue
+				}
+				resolvedURL, err := pageScraper.ResolveFinalDocumentURL(ctx, absoluteLink) // Follow redirects to the real file
+				if err != nil {                                                            // Not a PDF or unreachable
+					slog.Warn("skipping download link", "url", link, "error", err)
+					continue
+				}
+				pdfLinks = append(pdfLinks, resolvedURL) // Queue the resolved PDF URL
+			}
+		}
+	} else {
+		slog.Warn("no page HTML to extract links from", "url", seedURL) // Log message if the page couldn't be loaded
+	}
+
+	if config.CrawlDepth > 0 { // Follow links to SDS sub-pages as well
+		pdfLinks = append(pdfLinks, pageScraper.Crawl(ctx, seedURL, config.CrawlDepth, config.allowedHosts())...) // Merge PDFs found while crawling
+	}
+	return pdfLinks, labels
+}
+
+// linksFromHTMLFile extracts the document links and their labels from a saved page at path, without
+// touching the network; relative links resolve against the base URL like scraped ones
+func linksFromHTMLFile(path string, pageScraper *scraper.Scraper, config Config) ([]string, map[string]storage.LinkLabel, error) {
+	htmlContent, err := storage.ReadAFileAsString(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pageDocumentLinks(context.Background(), pageScraper, config, htmlContent)
+}
+
+// pageDocumentLinks extracts the document links in a page's HTML, keeping only the current sheet of each
+// product, along with the page's labels for them keyed the way main canonicalizes links
+func pageDocumentLinks(ctx context.Context, pageScraper *scraper.Scraper, config Config, htmlContent string) ([]string, map[string]storage.LinkLabel, error) {
+	pdfLinks, err := scraper.ExtractDocumentLinks(htmlContent, pageScraper.Extensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	pageLabels, err := scraper.ExtractDocumentLabels(htmlContent, pageScraper.Extensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels := make(map[string]storage.LinkLabel, len(pageLabels)) // Keyed the way main canonicalizes links
+	for href, label := range pageLabels {
+		labels[canonicalLink(href, config.BaseURL)] = label
+	}
+	return pageScraper.NewestRevisions(ctx, pdfLinks, labels, config.BaseURL), labels, nil
+}
+
+// pageUnchanged fetches seedURL with a plain GET and reports whether its raw HTML hashes the same as
+// when htmlPath was rendered, in which case the cache is reused if it has document links; it also
+// returns the new hash so a fresh render can record it
+func pageUnchanged(ctx context.Context, pageScraper *scraper.Scraper, pageHashes *storage.PageHashIndex, seedURL, htmlPath string) (bool, string) {
+	if !pageScraper.RobotsAllowed(ctx, seedURL) { // The render below is refused too
+		return false, ""
+	}
+	rawHTML := pageScraper.GetDataFromURL(ctx, seedURL) // Server HTML without JavaScript
+	if len(rawHTML) == 0 {                              // Nothing to compare; let Chrome try
+		return false, ""
+	}
+	digest := sha256.Sum256(rawHTML)
+	pageHash := hex.EncodeToString(digest[:])
+	if pageHash != pageHashes.Get(seedURL) || !storage.FileExists(htmlPath) { // Changed, or no render to fall back on
+		return false, pageHash
+	}
+	cachedHTML, err := storage.ReadAFileAsString(htmlPath)
+	if err != nil { // Unreadable cache; render again
+		return false, pageHash
+	}
+	if cachedLinks, err := scraper.ExtractDocumentLinks(cachedHTML, pageScraper.Extensions); err != nil || len(cachedLinks) == 0 { // A bad render is worth redoing
+		return false, pageHash
+	}
+	now := time.Now()
+	if err := os.Chtimes(htmlPath, now, now); err != nil { // Restart -html-ttl so the next run checks again later
+		slog.Warn("failed to touch cached HTML", "file", htmlPath, "error", err)
+	}
+	slog.Info("page unchanged since the last render, reusing cached HTML", "url", seedURL, "file", htmlPath)
+	return true, pageHash
+}
+
+// foundLinks receives each seed's links and labels, and then the sitemap's links, as soon as they're
+// found; seeds scraped in parallel call it concurrently
+type foundLinks func(links []string, labels map[string]storage.LinkLabel)
+
+// discoverLinks scrapes every seed and merges in the sitemap's links, sharing one Chrome across
+// seeds when ParallelScrape is set; it also returns the seed pages' labels for those links, and
+// passes each batch to onFound, when set, without waiting for the rest
+func discoverLinks(ctx context.Context, pageScraper *scraper.Scraper, config Config, onFound foundLinks) ([]string, map[string]storage.LinkLabel, error) {
+	if config.ParallelScrape > 0 && !config.NoChrome { // Render every seed in tabs of one browser
+		pageScraper.ChromePool = scraper.NewChromePool(ctx, config.ParallelScrape)
+		pageScraper.ChromePool.Logger = pageScraper.Logger // Log renders where the scraper logs
+		defer pageScraper.ChromePool.Close()
+	}
+	var pageHashes *storage.PageHashIndex // Raw-HTML hashes of the seed pages, when unchanged pages skip Chrome
+	if config.SkipUnchanged {
+		loaded, err := storage.LoadPageHashIndex(config.PageHashFile)
+		if err != nil { // Without the old hashes every page would look changed anyway
+			return nil, nil, err
+		}
+		pageHashes = loaded
+	}
+	pdfLinks, labels := scrapeSeeds(ctx, pageScraper, pageHashes, config, onFound) // PDF links gathered from every seed
+
+	if config.SitemapURL != "" { // Merge in links from the sitemap in case the page render missed some
+		sitemapLinks := pageScraper.ExtractDocumentLinksFromSitemap(ctx, config.SitemapURL) // Extract PDF links from the sitemap
+		slog.Info("read sitemap", "url", config.SitemapURL, "links", len(sitemapLinks))     // Log sitemap yield
+		pdfLinks = append(pdfLinks, sitemapLinks...)                                        // Merge with the page links
+		if onFound != nil {
+			onFound(sitemapLinks, nil)
+		}
+	}
+	return pdfLinks, labels, nil
+}
+
+// scrapeSeeds scrapes every seed and returns their links in seed order, with the labels of the first
+// seed that describes each link; with ParallelScrape set, up to that many seeds are scraped at once, and
+// with onFound set, each seed's links are passed to it as soon as that seed is done
+func scrapeSeeds(ctx context.Context, pageScraper *scraper.Scraper, pageHashes *storage.PageHashIndex, config Config, onFound foundLinks) ([]string, map[string]storage.LinkLabel) {
+	seedURLs := config.seedURLs()                                     // Index pages to scrape
+	seedLinks := make([][]string, len(seedURLs))                      // Links per seed, so the merged order doesn't depend on timing
+	seedLabels := make([]map[string]storage.LinkLabel, len(seedURLs)) // Labels per seed, merged in the same order
+	slots := make(chan struct{}, max(config.ParallelScrape, 1))
+	var waitGroup sync.WaitGroup
+	for seedIndex, seedURL := range seedURLs {
+		slots <- struct{}{} // Wait for a free slot
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-slots }()
+			seedLinks[seedIndex], seedLabels[seedIndex] = scrapeSeed(ctx, pageScraper, pageHashes, config, seedURL, htmlCachePath(seedURL, len(seedURLs))) // Page, download-handler and crawled links
+			slog.Info("scraped seed", "url", seedURL, "links", len(seedLinks[seedIndex]))
+			if onFound != nil { // Let downloads start before the other seeds finish
+				onFound(seedLinks[seedIndex], seedLabels[seedIndex])
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	var pdfLinks []string                    // Merge the seeds' links
+	labels := map[string]storage.LinkLabel{} // and their labels
+	for seedIndex, links := range seedLinks {
+		pdfLinks = append(pdfLinks, links...)
+		for link, label := range seedLabels[seedIndex] {
+			if _, labelled := labels[link]; !labelled { // Earlier seeds win
+				labels[link] = label
+			}
+		}
+	}
+	return pdfLinks, labels
+}
+
+// minExpectedPageBytes is the size below which a rendered index page is probably a captcha,
+// a consent wall, or a "JavaScript required" notice rather than the SDS list
+const minExpectedPageBytes = 2048
+
+// warnIfSuspiciousPage logs a warning when the page looks too small or link-free to be the real index,
+// so a blocked render shows up in the logs instead of as a run with nothing to download
+func warnIfSuspiciousPage(seedURL, htmlPath, htmlContent string, pdfLinkCount int) {
+	anchors, _ := scraper.ExtractLinks(htmlContent) // Any links at all, PDF or not; a page that doesn't parse has none
+	anchorCount := len(anchors)
+	if len(htmlContent) >= minExpectedPageBytes && anchorCount > 0 && pdfLinkCount > 0 {
+		return // Looks like a real index page
+	}
+	slog.Warn("page looks blocked or empty; inspect the saved HTML and rerun with -refresh",
+		"url", seedURL, "file", htmlPath, "bytes", len(htmlContent), "anchors", anchorCount, "pdf_links", pdfLinkCount, "min_bytes", minExpectedPageBytes)
+}
+
+// scrapeSeedHTML renders seedURL with Chrome through the proxy chosen for it, falling back to plain HTTP
+func scrapeSeedHTML(ctx context.Context, pageScraper *scraper.Scraper, config Config, seedURL string) string {
+	chromeOptions, err := seedChromeOptions(config, seedURL) // How Chrome loads the page
+	if err != nil {                                          // Handle a malformed environment proxy
+		slog.Error("failed to resolve proxy", "url", seedURL, "error", err)
+		return ""
+	}
+	htmlContent, err := pageScraper.ScrapePageHTML(ctx, seedURL, chromeOptions, !config.NoChrome) // Render page HTML
+	if errors.Is(err, scraper.ErrChromeTimeout) {                                                 // Too slow for Chrome; the plain HTML may still list the PDFs
+		slog.Warn("Chrome timed out, falling back to plain HTTP", "url", seedURL, "timeout", config.ChromeTimeout.Duration, "error", err)
+		htmlContent, err = pageScraper.ScrapePageHTML(ctx, seedURL, chromeOptions, false)
+	}
+	if err != nil { // Neither Chrome nor plain HTTP produced the page
+		slog.Error("failed to scrape page", "url", seedURL, "error", err)
+		return ""
+	}
+	return htmlContent
+}
+
+// seedChromeOptions returns the Chrome settings for loading seedURL, including the proxy chosen for it
+func seedChromeOptions(config Config, seedURL string) (scraper.ChromeOptions, error) {
+	chromeProxy, err := resolveProxy(config.Proxy, seedURL) // Proxy Chrome should use for this page
+	if err != nil {                                         // Handle a malformed environment proxy
+		return scraper.ChromeOptions{}, err
+	}
+	return scraper.ChromeOptions{
+		Proxy:           chromeProxy,
+		WaitSelector:    config.ChromeWaitSelector,
+		WaitTimeout:     config.ChromeWaitTimeout.Duration,
+		Timeout:         config.ChromeTimeout.Duration,
+		ExecPath:        config.ChromePath,
+		UserDataDir:     config.ChromeUserDataDir,
+		Headful:         config.ChromeHeadful,
+		RemoteURL:       config.ChromeRemoteURL,
+		Headers:         config.requestHeaders(),
+		Credentials:     config.credentialHeaders(),
+		CredentialHosts: config.credentialHosts(),
+		InsecureTLS:     config.InsecureSkipVerify,
+	}, nil
+}
+
+// replaceCachedHTML saves a freshly scraped page over the cached one, first keeping a date-stamped copy
+// of the old page when archive is set; the new page is written to a temporary file and renamed into
+// place, so a crash mid-write never leaves a half-written cache behind
+func replaceCachedHTML(path, htmlContent string, archive bool) {
+	if archive && storage.FileExists(path) { // Keep the old page for diffing
+		archivePath, err := storage.ArchiveFile(path, time.Now())
+		if err != nil {
+			slog.Error("failed to archive cached HTML", "file", path, "error", err)
+		} else {
+			slog.Info("archived cached HTML", "file", path, "archive", archivePath)
+		}
+	}
+	if err := storage.WriteFileAtomic(path, []byte(htmlContent), 0644); err != nil { // Replaces, never appends to, the old page
+		slog.Error("failed to save scraped HTML", "file", path, "error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main // Environment checks for scheduled runs
+
+import (
+	"context"       // For bounding the checks
+	"errors"        // For building check failures
+	"fmt"           // For the report
+	"io"            // For reading the seed page
+	"log/slog"      // For Chrome launch warnings
+	"net/http"      // For fetching the seed page
+	"os"            // For the scratch download directory
+	"path/filepath" // For the tracking file's directory
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // Test download
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/scraper"    // Chrome launch and link extraction
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/storage"    // Writability and filename helpers
+)
+
+// maxSelfTestPageBytes caps how much of the seed page -selftest reads while looking for a document link
+const maxSelfTestPageBytes = 16 << 20
+
+// selfTestResult is one line of the -selftest report
+type selfTestResult struct {
+	name    string // What was checked
+	skipped bool   // The check doesn't apply to this configuration
+	detail  string // What happened
+	err     error  // Why the check failed; nil when it passed or was skipped
+}
+
+// runSelfTest checks that Chrome launches, the first seed page is reachable, the output can be written
+// and one document downloads and validates, prints a report to stdout and returns whether every check passed
+func runSelfTest(ctx context.Context, config Config, httpClient *http.Client) bool {
+	seedURL := config.seedURLs()[0] // The page a real run scrapes first
+
+	results := []selfTestResult{checkChrome(ctx, config, seedURL)}
+	pageResult, pageHTML := checkSeedPage(ctx, httpClient, seedURL)
+	results = append(results, pageResult, checkOutputWritable(config))
+	results = append(results, checkTestDownload(ctx, config, httpClient, pageHTML))
+
+	failed := 0                      // Checks that didn't pass
+	for _, result := range results { // One line per check
+		status := "ok"
+		switch {
+		case result.skipped:
+			status = "skip"
+		case result.err != nil:
+			status = "FAIL"
+			result.detail = result.err.Error()
+			failed++
+		}
+		fmt.Printf("%-4s  %-8s  %s\n", status, result.name, result.detail)
+	}
+	if failed > 0 { // Say plainly that the environment isn't ready
+		fmt.Printf("selftest failed: %d of %d checks\n", failed, len(results))
+		return false
+	}
+	fmt.Println("selftest passed")
+	return true
+}
+
+// checkChrome launches Chrome with the settings the seed page would be rendered with
+func checkChrome(ctx context.Context, config Config, seedURL string) selfTestResult {
+	result := selfTestResult{name: "chrome"}
+	if config.NoChrome { // Pages are fetched over plain HTTP
+		result.skipped = true
+		result.detail = "-no-chrome is set"
+		return result
+	}
+	chromeOptions, err := seedChromeOptions(config, seedURL) // Same proxy, profile and executable as a real run
+	if err != nil {
+		result.err = fmt.Errorf("failed to resolve proxy: %w", err)
+		return result
+	}
+	if err := scraper.CheckChrome(ctx, chromeOptions, slog.Default()); err != nil {
+		result.err = err
+		return result
+	}
+	result.detail = "browser started"
+	if chromeOptions.RemoteURL != "" {
+		result.detail = "connected to " + chromeOptions.RemoteURL
+	}
+	return result
+}
+
+// checkSeedPage fetches seedURL over plain HTTP and returns its HTML for finding a test document
+func checkSeedPage(ctx context.Context, httpClient *http.Client, seedURL string) (selfTestResult, string) {
+	result := selfTestResult{name: "page"}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, seedURL, nil) // Plain GET, like the Chrome fallback
+	if err != nil {
+		result.err = fmt.Errorf("failed to build request for %s: %w", seedURL, err)
+		return result, ""
+	}
+	resp, err := httpClient.Do(request) // Same client, proxy and headers as a real run
+	if err != nil {
+		result.err = fmt.Errorf("%s is unreachable: %w", seedURL, err)
+		return result, ""
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSelfTestPageBytes))
+	if err != nil {
+		result.err = fmt.Errorf("failed to read %s: %w", seedURL, err)
+		return result, ""
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 { // Blocked, moved or down
+		result.err = fmt.Errorf("%s answered %s", seedURL, resp.Status)
+		return result, ""
+	}
+	result.detail = fmt.Sprintf("%s answered %s with %d bytes", seedURL, resp.Status, len(body))
+	return result, string(body)
+}
+
+// checkOutputWritable checks the output directory and the tracking file's directory, or their
+// nearest existing parents when a real run would still have to create them
+func checkOutputWritable(config Config) selfTestResult {
+	result := selfTestResult{name: "output"}
+	trackingFile := config.LinkFile // Where processed links are recorded
+	if config.Database != "" {
+		trackingFile = config.Database
+	}
+	for _, directory := range []string{config.OutputDir, filepath.Dir(trackingFile)} {
+		existing := directory // Closest directory that exists now; the run creates the rest
+		for !storage.DirectoryExists(existing) && filepath.Dir(existing) != existing {
+			existing = filepath.Dir(existing)
+		}
+		if err := storage.CheckWritable(existing); err != nil {
+			result.err = err
+			return result
+		}
+	}
+	result.detail = fmt.Sprintf("%s and %s are writable", config.OutputDir, filepath.Dir(trackingFile))
+	return result
+}
+
+// checkTestDownload downloads -selftest-url, or the first document linked from pageHTML, into a scratch
+// directory with the real run's validation and limits, then removes it
+func checkTestDownload(ctx context.Context, config Config, httpClient *http.Client, pageHTML string) selfTestResult {
+	result := selfTestResult{name: "download"}
+	link := config.SelfTestURL // Known document, if one was given
+	if link == "" {            // Fall back to whatever the seed page links to
+		documentLinks, err := scraper.ExtractDocumentLinks(pageHTML, config.Extensions)
+		if err != nil {
+			result.err = fmt.Errorf("failed to read the seed page's links: %w", err)
+			return result
+		}
+		if len(documentLinks) == 0 {
+			result.err = errors.New("no document link on the seed page to test with; set -selftest-url")
+			return result
+		}
+		link = canonicalLink(documentLinks[0], config.BaseURL)
+	}
+
+	scratchDir, err := os.MkdirTemp("", "selftest-*") // Keep the test file and its hash index out of the real output
+	if err != nil {
+		result.err = fmt.Errorf("failed to create scratch directory: %w", err)
+		return result
+	}
+	defer os.RemoveAll(scratchDir)
+
+	hashIndex, err := storage.LoadContentHashIndex(filepath.Join(scratchDir, "hashes.json")) // Empty, so nothing counts as a duplicate
+	if err != nil {
+		result.err = err
+		return result
+	}
+	downloadClient := *httpClient // Downloads are bounded by DownloadTimeout instead
+	downloadClient.Timeout = 0
+	testDownloader := &downloader.Downloader{ // Same checks as a real download, without the run's state
+		HTTPClient:      &downloadClient,
+		RateLimiter:     downloader.NewHostRateLimiter(0),
+		HashIndex:       hashIndex,
+		MaxSize:         int64(config.MaxSize),
+		MinSize:         int64(config.MinSize),
+		ContentTypes:    config.ContentTypes,
+		DownloadTimeout: config.DownloadTimeout.Duration,
+		StallTimeout:    config.StallTimeout.Duration,
+	}
+	filePath := storage.NewFilenameRegistry(nil, nil, "").PathFor(scratchDir, link) // Keeps the extension the signature check relies on
+	record, err := testDownloader.DownloadPDF(ctx, link, filePath, nil)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.detail = fmt.Sprintf("%s: %d bytes, sha256 %s", link, record.Size, record.SHA256)
+	return result
+}
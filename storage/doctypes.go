@@ -0,0 +1,61 @@
+package storage // Which links and responses count as documents worth saving
+
+import (
+	"mime"    // For parsing Content-Type headers
+	"net/url" // For finding a link's path
+	"path"    // For path extensions
+	"strings" // For case folding
+)
+
+// DefaultExtensions and DefaultContentTypes keep the downloader PDF-only unless configured otherwise
+var (
+	DefaultExtensions   = []string{".pdf"}
+	DefaultContentTypes = []string{"application/pdf"}
+)
+
+// fileSignatures maps extensions to the bytes files of that type start with
+var fileSignatures = map[string][]byte{
+	".pdf":  []byte("%PDF-"),                            // PDF header
+	".docx": []byte("PK\x03\x04"),                       // Office Open XML is a ZIP archive
+	".xlsx": []byte("PK\x03\x04"),                       // Office Open XML is a ZIP archive
+	".pptx": []byte("PK\x03\x04"),                       // Office Open XML is a ZIP archive
+	".doc":  []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), // Legacy OLE compound file
+	".xls":  []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), // Legacy OLE compound file
+}
+
+// HasDocumentExtension reports whether the path of location ends in one of extensions, ignoring case,
+// the query string and the fragment
+func HasDocumentExtension(location string, extensions []string) bool {
+	parsedURL, err := url.Parse(location) // Parse so query strings don't hide the extension
+	if err != nil {                       // Skip malformed links
+		return false
+	}
+	linkExtension := strings.ToLower(path.Ext(parsedURL.Path)) // e.g. .pdf
+	for _, extension := range extensions {
+		if linkExtension != "" && linkExtension == strings.ToLower(extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentTypeAllowed reports whether the media type of contentType is one of allowed; parameters
+// such as "; charset=binary" are ignored
+func ContentTypeAllowed(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType) // Drop parameters
+	if err != nil {                                       // Fall back to the raw header
+		mediaType = strings.TrimSpace(contentType)
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(mediaType, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// FileSignature returns the bytes a file named like filePath should start with, or nil when its
+// extension has no known signature
+func FileSignature(filePath string) []byte {
+	return fileSignatures[strings.ToLower(path.Ext(filePath))]
+}
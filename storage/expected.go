@@ -0,0 +1,25 @@
+package storage // Known-good hashes to verify downloads against
+
+import (
+	"encoding/json" // For decoding the expected-hash file
+	"fmt"           // For formatted error messages
+	"os"            // For reading the file
+	"strings"       // For normalizing hex digests
+)
+
+// LoadExpectedHashes reads a JSON object mapping PDF URLs to their expected hex SHA-256
+func LoadExpectedHashes(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path) // Read the expected-hash file
+	if err != nil {                   // A missing file is an error: verification was asked for
+		return nil, fmt.Errorf("failed to read expected hashes %s: %w", path, err)
+	}
+
+	var expected map[string]string                             // URL -> SHA-256
+	if err := json.Unmarshal(content, &expected); err != nil { // Decode the mapping
+		return nil, fmt.Errorf("failed to parse expected hashes %s: %w", path, err)
+	}
+	for link, digest := range expected { // Compare digests case-insensitively
+		expected[link] = strings.ToLower(strings.TrimSpace(digest))
+	}
+	return expected, nil
+}
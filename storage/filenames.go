@@ -0,0 +1,194 @@
+package storage // Disambiguation of URLs that map to the same local filename
+
+import (
+	"bytes"         // For rendering name templates
+	"crypto/sha256" // For hashing URLs into short suffixes
+	"encoding/hex"  // For encoding the suffix as text
+	"fmt"           // For formatting errors
+	"log/slog"      // For structured logging
+	"net/url"       // For parsing and building URLs
+	"path"          // For manipulating slash-separated paths
+	"path/filepath" // For joining and splitting file paths
+	"regexp"        // For regular expressions
+	"strings"       // For trimming the extension
+	"text/template" // For custom filename schemes
+)
+
+// FilenameRegistry remembers which source URL owns each local file path
+type FilenameRegistry struct {
+	owners       map[string]string  // File path -> source URL
+	savedPaths   map[string]string  // Source URL -> file path it was saved to
+	nameTemplate *template.Template // Custom naming scheme; nil uses URLToSafeFilename
+	partition    string             // Subdirectory for new files, e.g. 2024-01-31; empty saves directly into the output directory
+}
+
+// NewFilenameRegistry seeds the registry with the owners recorded in earlier manifests; nameTemplate
+// comes from ParseNameTemplate and may be nil for the default names, and a non-empty partition puts
+// new files in that subdirectory while files from earlier partitions stay where they are
+func NewFilenameRegistry(records []DownloadRecord, nameTemplate *template.Template, partition string) *FilenameRegistry {
+	registry := &FilenameRegistry{ // Empty registry
+		owners:       make(map[string]string),
+		savedPaths:   make(map[string]string),
+		nameTemplate: nameTemplate,
+		partition:    partition,
+	}
+	for _, record := range records { // Every file saved by an earlier run
+		registry.savedPaths[record.URL] = record.Filename
+		if _, claimed := registry.owners[record.Filename]; record.Duplicate || claimed { // The URL that saved the file owns it, not its duplicates
+			continue
+		}
+		registry.owners[record.Filename] = record.URL
+	}
+	return registry
+}
+
+// PathFor returns where link should be saved in outputDir, adding a short URL hash when a different URL already owns the plain name
+func (registry *FilenameRegistry) PathFor(outputDir, link string) string {
+	name := registry.fileName(link)            // Name relative to the output directory or partition
+	filePath := filepath.Join(outputDir, name) // Plain name derived from the URL
+	if registry.partition != "" {              // Reuse a copy from any partition before starting a new one
+		if existing := registry.partitionedCopy(outputDir, name, link); existing != "" {
+			return existing
+		}
+		filePath = filepath.Join(outputDir, registry.partition, name)
+	}
+	owner, claimed := registry.owners[filePath] // Who saved this name before
+	if !claimed || owner == link {              // Free, or already ours
+		registry.owners[filePath] = link
+		return filePath
+	}
+
+	extension := filepath.Ext(filePath)                                                             // Keep the extension at the end
+	disambiguated := strings.TrimSuffix(filePath, extension) + "-" + shortURLHash(link) + extension // e.g. msds-a1b2c3.pdf
+	registry.owners[disambiguated] = link                                                           // Claim the disambiguated name
+	return disambiguated
+}
+
+// partitionedCopy finds link's file in any partition of outputDir, first from the manifest and then
+// by name, and returns "" when there is none so a new copy goes into the current partition
+func (registry *FilenameRegistry) partitionedCopy(outputDir, name, link string) string {
+	if savedPath, saved := registry.savedPaths[link]; saved && FileExists(savedPath) { // Where the manifest says it went
+		return savedPath
+	}
+	matches, _ := filepath.Glob(filepath.Join(outputDir, "*", name)) // Same name in any partition; a bad pattern just finds nothing
+	for _, match := range matches {
+		if owner, claimed := registry.owners[match]; (!claimed || owner == link) && FileExists(match) { // Not another URL's file
+			registry.owners[match] = link
+			return match
+		}
+	}
+	return ""
+}
+
+// fileName returns link's name relative to the output directory, from the template when one is set
+func (registry *FilenameRegistry) fileName(link string) string {
+	if registry.nameTemplate == nil { // Default naming
+		return URLToSafeFilename(link)
+	}
+	name, err := executeNameTemplate(registry.nameTemplate, link) // Custom naming
+	if err != nil {                                               // Fall back rather than lose the file
+		slog.Warn("name template failed, using default name", "url", link, "error", err)
+		return URLToSafeFilename(link)
+	}
+	return name
+}
+
+// NameFields are the values a -name-template can use
+type NameFields struct {
+	Host string // Lowercased host name, e.g. www.duragloss.com
+	Dir  string // Sanitized directory part of the URL path, e.g. sds/product, or "." at the root
+	Base string // Sanitized file name without its extension, e.g. msds_123
+	Hash string // Six hex digits of the URL's SHA-256
+	Ext  string // Extension including the dot, e.g. .pdf
+}
+
+// MirrorNameTemplate recreates the URL path under the output directory
+const MirrorNameTemplate = "{{.Dir}}/{{.Base}}{{.Ext}}"
+
+// ParseNameTemplate parses a filename template such as "{{.Host}}/{{.Base}}{{.Ext}}" and checks it
+// renders a safe relative path; an empty text returns nil for the default names
+func ParseNameTemplate(text string) (*template.Template, error) {
+	if text == "" { // Default naming
+		return nil, nil
+	}
+	nameTemplate, err := template.New("name").Parse(text) // Catch syntax errors
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template: %w", err)
+	}
+	if _, err := executeNameTemplate(nameTemplate, "https://www.duragloss.com/sds/sample.pdf"); err != nil { // Catch unknown fields and unsafe paths
+		return nil, fmt.Errorf("invalid name template: %w", err)
+	}
+	return nameTemplate, nil
+}
+
+// executeNameTemplate renders nameTemplate for link into a cleaned path that stays inside the output directory
+func executeNameTemplate(nameTemplate *template.Template, link string) (string, error) {
+	parsedURL, err := url.Parse(link) // Parse the link for its host
+	if err != nil {
+		return "", err
+	}
+	safeName := URLToSafeFilename(link) // Sanitized base name with extension
+	extension := filepath.Ext(safeName) // e.g. .pdf
+	fields := NameFields{
+		Host: strings.ToLower(parsedURL.Hostname()),
+		Dir:  safeDirectory(parsedURL.Path),
+		Base: strings.TrimSuffix(safeName, extension),
+		Hash: shortURLHash(link),
+		Ext:  extension,
+	}
+
+	var rendered bytes.Buffer                                       // Template output
+	if err := nameTemplate.Execute(&rendered, fields); err != nil { // Unknown fields fail here
+		return "", err
+	}
+	name := filepath.Clean(filepath.FromSlash(rendered.String()))                                                        // Normalize separators and dot segments
+	if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) { // Must name a file inside the output directory
+		return "", fmt.Errorf("name %q is not a relative file path", rendered.String())
+	}
+	return name, nil
+}
+
+// safeDirectory sanitizes each directory segment of urlPath the way URLToSafeFilename sanitizes the
+// name, dropping empty and dot segments, and returns "." for files at the root
+func safeDirectory(urlPath string) string {
+	var segments []string                                           // Sanitized directory names
+	for _, segment := range strings.Split(path.Dir(urlPath), "/") { // e.g. ["", "sds", "product"]
+		if decoded, err := url.PathUnescape(segment); err == nil { // Decode %20 and friends
+			segment = decoded
+		}
+		segment = unsafeFilenameCharacters.ReplaceAllString(strings.ToLower(segment), "_")
+		if segment == "" || strings.Trim(segment, ".") == "" { // Empty, "." and ".." segments can't escape the output directory
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 { // File at the URL root
+		return "."
+	}
+	return strings.Join(segments, "/")
+}
+
+// shortURLHash returns the first six hex digits of the URL's SHA-256
+func shortURLHash(link string) string {
+	sum := sha256.Sum256([]byte(link))    // Hash the full URL
+	return hex.EncodeToString(sum[:])[:6] // Six hex digits are plenty to tell a handful of URLs apart
+}
+
+// URLToSafeFilename sanitizes a URL into a filesystem-safe filename
+func URLToSafeFilename(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL) // Parse the raw URL
+	if err != nil {                     // Handle parse error
+		return "" // Return empty string if parse fails
+	}
+	base := path.Base(parsedURL.Path)       // Get the file name portion of the path
+	decoded, err := url.QueryUnescape(base) // Decode URL-encoded string
+	if err != nil {                         // Fallback if decoding fails
+		decoded = base
+	}
+	decoded = strings.ToLower(decoded)                              // Convert to lowercase
+	safe := unsafeFilenameCharacters.ReplaceAllString(decoded, "_") // Replace invalid characters with underscore
+	return safe                                                     // Return sanitized filename
+}
+
+// unsafeFilenameCharacters matches runs of characters that aren't kept in file and directory names
+var unsafeFilenameCharacters = regexp.MustCompile(`[^a-z0-9._-]+`)
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPathForDisambiguatesSameBaseName(t *testing.T) {
+	registry := NewFilenameRegistry(nil, nil, "")
+	first := registry.PathFor("PDFs", "https://www.duragloss.com/sds/wax/msds.pdf")
+	second := registry.PathFor("PDFs", "https://www.duragloss.com/sds/polish/msds.pdf")
+
+	if want := filepath.Join("PDFs", "msds.pdf"); first != want {
+		t.Errorf("first URL got %q, want %q", first, want)
+	}
+	if want := filepath.Join("PDFs", "msds-"+shortURLHash("https://www.duragloss.com/sds/polish/msds.pdf")+".pdf"); second != want {
+		t.Errorf("second URL got %q, want %q", second, want)
+	}
+	if again := registry.PathFor("PDFs", "https://www.duragloss.com/sds/wax/msds.pdf"); again != first {
+		t.Errorf("first URL asked again got %q, want %q", again, first)
+	}
+}
+
+func TestPathForKeepsOwnersFromManifest(t *testing.T) {
+	records := []DownloadRecord{ // Sorted by URL, as WriteManifest leaves them
+		{URL: "https://www.duragloss.com/sds/polish/msds.pdf", Filename: filepath.Join("PDFs", "msds-"+shortURLHash("https://www.duragloss.com/sds/polish/msds.pdf")+".pdf")},
+		{URL: "https://www.duragloss.com/sds/wax/msds.pdf", Filename: filepath.Join("PDFs", "msds.pdf")},
+	}
+	registry := NewFilenameRegistry(records, nil, "")
+	for _, record := range records {
+		if got := registry.PathFor("PDFs", record.URL); got != record.Filename {
+			t.Errorf("PathFor(%q) = %q, want %q", record.URL, got, record.Filename)
+		}
+	}
+}
+
+func TestPathForIgnoresDuplicateRecords(t *testing.T) {
+	original := "https://x/other/a.pdf"
+	duplicate := "https://x/sds/b.pdf"
+	tests := []struct {
+		name    string
+		records []DownloadRecord
+	}{
+		{"flagged duplicate", []DownloadRecord{
+			{URL: duplicate, Filename: filepath.Join("PDFs", "a.pdf"), Duplicate: true}, // Sorts first, but mustn't claim the name
+			{URL: original, Filename: filepath.Join("PDFs", "a.pdf")},
+		}},
+		{"unflagged duplicate after the owner", []DownloadRecord{ // Manifests from before the flag
+			{URL: original, Filename: filepath.Join("PDFs", "a.pdf")},
+			{URL: duplicate, Filename: filepath.Join("PDFs", "a.pdf")},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewFilenameRegistry(test.records, nil, "")
+			if got, want := registry.PathFor("PDFs", original), filepath.Join("PDFs", "a.pdf"); got != want {
+				t.Errorf("PathFor(original) = %q, want %q", got, want)
+			}
+			if got, want := registry.PathFor("PDFs", duplicate), filepath.Join("PDFs", "b.pdf"); got != want {
+				t.Errorf("PathFor(duplicate) = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestURLToSafeFilename(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.duragloss.com/sds/wax.pdf", "wax.pdf"},
+		{"https://www.duragloss.com/sds/Paste%20Wax.pdf", "paste_wax.pdf"}, // Percent-encoding is decoded first
+		{"https://www.duragloss.com/sds/PASTE-WAX.PDF", "paste-wax.pdf"},   // Names are lowercased
+		{"https://www.duragloss.com/sds/wax.pdf?v=2&lang=en", "wax.pdf"},   // The query isn't part of the name
+		{"https://www.duragloss.com/sds/wax%20(new)!.pdf", "wax_new_.pdf"}, // Runs of unsafe characters become one underscore
+		{"https://www.duragloss.com/sds/%C3%A9t%C3%A9.pdf", "_t_.pdf"},     // Non-ASCII characters are unsafe
+		{"http://[::1", ""}, // Unparseable
+	}
+	for _, test := range tests {
+		if got := URLToSafeFilename(test.url); got != test.want {
+			t.Errorf("URLToSafeFilename(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package storage // Local file helpers shared by the scraper and downloader
+
+import (
+	"fmt"           // For wrapping errors
+	"os"            // For file and system operations
+	"path/filepath" // For splitting off file extensions
+	"strings"       // For trimming the extension
+	"time"          // For archive date stamps
+)
+
+// FileExists returns true if a file exists at the given path
+func FileExists(filename string) bool {
+	info, err := os.Stat(filename) // Get file info
+	if err != nil {                // If stat fails
+		return false
+	}
+	return !info.IsDir() // Return true if it's a file, not directory
+}
+
+// DirectoryExists returns true if a directory exists at the given path
+func DirectoryExists(path string) bool {
+	directory, err := os.Stat(path) // Get file/directory info
+	if err != nil {                 // If stat fails
+		return false
+	}
+	return directory.IsDir() // Return true if it's a directory
+}
+
+// CreateDirectory creates a directory and any missing parents with the specified permissions
+func CreateDirectory(path string, permission os.FileMode) error {
+	if err := os.MkdirAll(path, permission); err != nil { // Attempt to create the directory tree
+		return fmt.Errorf("failed to create directory %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadAFileAsString reads a file from disk and returns its contents as a string; a missing file
+// is reported as an error matching fs.ErrNotExist so callers can tell it apart from an empty one
+func ReadAFileAsString(path string) (string, error) {
+	content, err := os.ReadFile(path) // Read file contents
+	if err != nil {                   // Handle file read error
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	return string(content), nil // Return content as string
+}
+
+// WriteFileAtomic writes content to a temporary file next to path and renames it into place, so an
+// interrupted write leaves the previous file intact instead of a truncated one
+func WriteFileAtomic(path string, content []byte, permission os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*") // Same directory, so the rename stays on one file system
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tempPath := tempFile.Name()
+	_, err = tempFile.Write(content)
+	if err == nil {
+		err = tempFile.Sync() // Make sure the bytes are on disk before the rename makes them visible
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, permission) // CreateTemp always uses 0600
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath) // Discard the partial copy
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ArchiveFile renames path to a copy stamped with the given day, e.g. page.html becomes page-20240131.html,
+// and returns the archive path; an archive from the same day is replaced
+func ArchiveFile(path string, day time.Time) (string, error) {
+	extension := filepath.Ext(path)                                                               // Keep the extension last
+	archivePath := strings.TrimSuffix(path, extension) + "-" + day.Format("20060102") + extension // Date-stamped sibling
+	if err := os.Rename(path, archivePath); err != nil {                                          // Move the old file aside
+		return "", fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	return archivePath, nil
+}
+
+// FileOlderThan reports whether the file at path was last modified more than age ago
+func FileOlderThan(path string, age time.Duration) bool {
+	info, err := os.Stat(path) // Get file info
+	if err != nil {            // A missing file counts as fresh; callers check existence separately
+		return false
+	}
+	return time.Since(info.ModTime()) > age // Compare against the modification time
+}
+
+// CheckWritable confirms files can be created in directory by creating and removing a temporary one
+func CheckWritable(directory string) error {
+	probe, err := os.CreateTemp(directory, ".write-check-*") // Try to create a file
+	if err != nil {                                          // Missing, read-only, or not permitted
+		return fmt.Errorf("directory %s is not writable: %w", directory, err)
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil { // Clean up the probe
+		return fmt.Errorf("failed to remove write check file in %s: %w", directory, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package storage // Content-hash deduplication for downloaded PDFs
+
+import (
+	"encoding/json" // For persisting the index as JSON
+	"errors"        // For detecting a missing index file
+	"fmt"           // For formatted error messages
+	"io/fs"         // For the not-exist sentinel error
+	"os"            // For reading, writing, and renaming files
+	"sync"          // For guarding the index across workers
+)
+
+// ContentHashIndex maps the SHA-256 of each saved PDF to the file that holds it
+type ContentHashIndex struct {
+	mutex  sync.Mutex        // Guards hashes and the on-disk copy
+	path   string            // JSON file the index is persisted to
+	hashes map[string]string // Hex SHA-256 -> saved file path
+}
+
+// LoadContentHashIndex reads the index from disk, starting empty if the file doesn't exist yet
+func LoadContentHashIndex(path string) (*ContentHashIndex, error) {
+	index := &ContentHashIndex{path: path, hashes: make(map[string]string)} // Empty index bound to the path
+
+	content, err := os.ReadFile(path)   // Read the persisted index
+	if errors.Is(err, fs.ErrNotExist) { // First run: nothing to load
+		return index, nil
+	}
+	if err != nil { // Handle read error
+		return index, fmt.Errorf("failed to read hash index %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, &index.hashes); err != nil { // Decode the stored hashes
+		return index, fmt.Errorf("failed to parse hash index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// NewContentHashIndex returns an empty index that lives only in memory
+func NewContentHashIndex() *ContentHashIndex {
+	return &ContentHashIndex{hashes: make(map[string]string)}
+}
+
+// StoreUnique renames tempPath to filePath unless another file already holds the same content.
+// It returns the path of the existing copy when the content is a duplicate.
+func (index *ContentHashIndex) StoreUnique(hash, tempPath, filePath string) (string, error) {
+	index.mutex.Lock()         // Serialize lookups and writes across workers
+	defer index.mutex.Unlock() // Release the lock when done
+
+	existing, found := index.hashes[hash]                      // Look up the content hash
+	if found && existing != filePath && FileExists(existing) { // Same bytes already saved under another name
+		os.Remove(tempPath) // Drop the redundant copy
+		return existing, nil
+	}
+
+	if err := os.Rename(tempPath, filePath); err != nil { // Atomically move the complete file into place
+		os.Remove(tempPath) // Discard the temporary file
+		return "", fmt.Errorf("failed to move PDF into place: %w", err)
+	}
+
+	for oldHash, path := range index.hashes { // A refreshed file no longer holds its old content
+		if path == filePath {
+			delete(index.hashes, oldHash)
+		}
+	}
+	index.hashes[hash] = filePath // Remember where this content lives
+	return "", index.save()       // Persist the updated index
+}
+
+// LinkDuplicate makes filePath a hard link to original, replacing any file already at filePath, so the
+// same content is reachable under both names without taking up space twice
+func LinkDuplicate(original, filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) { // Links can't overwrite
+		return fmt.Errorf("failed to replace %s: %w", filePath, err)
+	}
+	if err := os.Link(original, filePath); err != nil { // Fails across file systems and on some network shares
+		return fmt.Errorf("failed to link %s to %s: %w", filePath, original, err)
+	}
+	return nil
+}
+
+// save writes the index to disk; the caller must hold the mutex
+func (index *ContentHashIndex) save() error {
+	if index.path == "" { // In-memory index from NewContentHashIndex
+		return nil
+	}
+	content, err := json.MarshalIndent(index.hashes, "", "  ") // Encode the hashes as readable JSON
+	if err != nil {                                            // Handle encode error
+		return fmt.Errorf("failed to encode hash index: %w", err)
+	}
+	if err := WriteFileAtomic(index.path, content, 0644); err != nil { // Replace the index file whole
+		return fmt.Errorf("failed to save hash index: %w", err)
+	}
+	return nil
+}
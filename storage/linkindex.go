@@ -0,0 +1,22 @@
+package storage // Backend-neutral view of the processed-link store
+
+import "time" // For first-seen and download timestamps
+
+// LinkIndex records which PDF links have been processed; LinkStore keeps it in a JSON file and
+// SQLiteStore in a database, and callers shouldn't care which
+type LinkIndex interface {
+	Processed(link string) bool                                               // Whether link was recorded before
+	SeenSince(link string, since time.Time, strict bool) bool                 // Whether link was first seen at or after since
+	MarkProcessed(link string, download *DownloadRecord, now time.Time) error // Record link, with its download when one happened
+	Flush() error                                                             // Persist anything still buffered
+	Close() error                                                             // Release the backend
+}
+
+// OpenLinkIndex opens the SQLite database at dbPath when it's set, and the JSON store at linkFile
+// (importing the legacy link list on first use) otherwise
+func OpenLinkIndex(dbPath, linkFile string) (LinkIndex, error) {
+	if dbPath != "" { // Database backend requested
+		return OpenSQLiteStore(dbPath, linkFile)
+	}
+	return LoadLinkStore(linkFile, LegacyLinkFile)
+}
@@ -0,0 +1,57 @@
+package storage // Discovered link list shared between the scrape and download phases
+
+import (
+	"encoding/json" // For persisting the list as JSON
+	"fmt"           // For formatted error messages
+	"os"            // For reading and writing the list file
+)
+
+// listedLink is one entry of the link list file
+type listedLink struct {
+	URL          string `json:"url"`                     // Absolute, normalized document URL
+	Title        string `json:"title,omitempty"`         // Product name from the page, if any
+	Category     string `json:"category,omitempty"`      // Section heading from the page, if any
+	RevisionDate string `json:"revision_date,omitempty"` // Revision date next to the link, if any
+}
+
+// SaveLinkList writes links, in order, to path as a JSON array, with each link's label from labels
+func SaveLinkList(path string, links []string, labels map[string]LinkLabel) error {
+	entries := make([]listedLink, 0, len(links)) // Always an array, even when nothing was found
+	for _, link := range links {
+		label := labels[link] // Zero label when the page gave none
+		entries = append(entries, listedLink{URL: link, Title: label.Title, Category: label.Category, RevisionDate: label.RevisionDate})
+	}
+	content, err := json.MarshalIndent(entries, "", "  ") // Encode the list as readable JSON
+	if err != nil {                                       // Handle encode error
+		return fmt.Errorf("failed to encode link list: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil { // Write the list file
+		return fmt.Errorf("failed to write link list %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadLinkList reads a list written by SaveLinkList, returning the links in order and their labels
+func LoadLinkList(path string) ([]string, map[string]LinkLabel, error) {
+	content, err := os.ReadFile(path) // A missing file is an error: there is nothing to download
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read link list %s: %w", path, err)
+	}
+	var entries []listedLink
+	if err := json.Unmarshal(content, &entries); err != nil { // Decode the stored list
+		return nil, nil, fmt.Errorf("failed to parse link list %s: %w", path, err)
+	}
+
+	links := make([]string, 0, len(entries)) // URLs in file order
+	labels := make(map[string]LinkLabel)     // Labels for the entries that have one
+	for _, entry := range entries {
+		if entry.URL == "" { // Hand-edited entry without a URL
+			continue
+		}
+		links = append(links, entry.URL)
+		if entry.Title != "" || entry.Category != "" || entry.RevisionDate != "" {
+			labels[entry.URL] = LinkLabel{Title: entry.Title, Category: entry.Category, RevisionDate: entry.RevisionDate}
+		}
+	}
+	return links, labels, nil
+}
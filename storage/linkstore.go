@@ -0,0 +1,139 @@
+package storage // Structured store of processed PDF links
+
+import (
+	"bufio"         // For reading the legacy newline-separated link list
+	"encoding/json" // For persisting the store as JSON
+	"errors"        // For detecting a missing store
+	"fmt"           // For formatted error messages
+	"io/fs"         // For the not-exist sentinel error
+	"os"            // For reading and writing the store
+	"strings"       // For trimming legacy lines
+	"sync"          // For guarding the store across workers
+	"time"          // For first-seen and download timestamps
+)
+
+// LegacyLinkFile is the flat newline list used before the structured store existed
+const LegacyLinkFile = "pdf_links.txt"
+
+// LinkRecord is what we know about one processed PDF link
+type LinkRecord struct {
+	FirstSeen      *time.Time `json:"first_seen,omitempty"`      // When the link was first processed; nil if imported without a date
+	LastDownloaded *time.Time `json:"last_downloaded,omitempty"` // When the PDF was last fetched successfully
+	Size           int64      `json:"size,omitempty"`            // Byte size of the last successful download
+}
+
+// LinkStore maps each processed URL to its record and persists them as JSON. It is safe for concurrent
+// use: every tracking write from the download workers goes through its mutex.
+type LinkStore struct {
+	mutex   sync.Mutex             // Guards records and the on-disk copy
+	path    string                 // JSON file the store is persisted to
+	records map[string]*LinkRecord // URL -> record
+}
+
+// LoadLinkStore reads the store from path, importing the legacy link list when the store doesn't exist yet
+func LoadLinkStore(path, legacyPath string) (*LinkStore, error) {
+	store := &LinkStore{path: path, records: make(map[string]*LinkRecord)} // Empty store bound to the path
+
+	content, err := os.ReadFile(path)   // Read the persisted store
+	if errors.Is(err, fs.ErrNotExist) { // First run with the structured store
+		return store, store.importLegacy(legacyPath)
+	}
+	if err != nil { // Handle read error
+		return store, fmt.Errorf("failed to read link store %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, &store.records); err != nil { // Decode the records
+		return store, fmt.Errorf("failed to parse link store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// importLegacy adds every line of the legacy link list as a record with no known first-seen time
+func (store *LinkStore) importLegacy(legacyPath string) error {
+	file, err := os.Open(legacyPath)    // Open the legacy list
+	if errors.Is(err, fs.ErrNotExist) { // Nothing to import
+		return nil
+	}
+	if err != nil { // Handle open error
+		return fmt.Errorf("failed to open legacy link file %s: %w", legacyPath, err)
+	}
+	defer file.Close() // Ensure file is closed
+
+	scanner := bufio.NewScanner(file) // Read the list line by line
+	for scanner.Scan() {
+		link := strings.TrimSpace(scanner.Text()) // One URL per line
+		if link != "" {
+			store.records[link] = &LinkRecord{} // Date unknown for imported links
+		}
+	}
+	if err := scanner.Err(); err != nil { // Handle read error
+		return fmt.Errorf("failed to read legacy link file %s: %w", legacyPath, err)
+	}
+	return nil
+}
+
+// Processed returns true if the link has been recorded before
+func (store *LinkStore) Processed(link string) bool {
+	store.mutex.Lock()         // Serialize access across workers
+	defer store.mutex.Unlock() // Release the lock when done
+
+	_, found := store.records[link] // Exact URL lookup
+	return found
+}
+
+// SeenSince returns true if link was first seen at or after since. Links not in the store are new and always match;
+// links with no recorded first-seen time match unless strict is set.
+func (store *LinkStore) SeenSince(link string, since time.Time, strict bool) bool {
+	store.mutex.Lock()         // Serialize access across workers
+	defer store.mutex.Unlock() // Release the lock when done
+
+	record, found := store.records[link] // Existing record, if any
+	if !found {                          // Never seen before, so first seen now
+		return true
+	}
+	if record.FirstSeen == nil { // Imported without a date
+		return !strict
+	}
+	return !record.FirstSeen.Before(since)
+}
+
+// MarkProcessed records that link was handled, noting the download when one was fetched
+func (store *LinkStore) MarkProcessed(link string, download *DownloadRecord, now time.Time) error {
+	store.mutex.Lock()         // Serialize access across workers
+	defer store.mutex.Unlock() // Release the lock when done
+
+	record, found := store.records[link] // Existing record, if any
+	if !found {                          // First time we've seen this link
+		record = &LinkRecord{FirstSeen: &now}
+		store.records[link] = record
+	}
+	if download != nil && download.Size > 0 { // A file was fetched this time
+		record.LastDownloaded = &now
+		record.Size = download.Size
+	}
+	return store.save()
+}
+
+// Flush writes the store to disk, e.g. on shutdown after an earlier save failed
+func (store *LinkStore) Flush() error {
+	store.mutex.Lock()         // Serialize access across workers
+	defer store.mutex.Unlock() // Release the lock when done
+
+	return store.save()
+}
+
+// Close flushes the store; the JSON file needs no other cleanup
+func (store *LinkStore) Close() error {
+	return store.Flush()
+}
+
+// save writes the store to disk; the caller must hold the mutex
+func (store *LinkStore) save() error {
+	content, err := json.MarshalIndent(store.records, "", "  ") // Encode the records as readable JSON, sorted by URL
+	if err != nil {                                             // Handle encode error
+		return fmt.Errorf("failed to encode link store: %w", err)
+	}
+	if err := WriteFileAtomic(store.path, content, 0644); err != nil { // Replace the store file whole
+		return fmt.Errorf("failed to save link store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMarkProcessedConcurrently is meant for go test -race: workers mark links while others read the store
+func TestMarkProcessedConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "links.json")
+	store, err := LoadLinkStore(path, filepath.Join(dir, "missing.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 64
+	now := time.Now()
+	var waitGroup sync.WaitGroup
+	for worker := range workers {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			link := fmt.Sprintf("https://www.duragloss.com/sds/%d.pdf", worker)
+			if err := store.MarkProcessed(link, &DownloadRecord{Size: int64(worker + 1)}, now); err != nil {
+				t.Errorf("MarkProcessed(%s): %v", link, err)
+			}
+			if err := store.MarkProcessed("https://www.duragloss.com/sds/shared.pdf", nil, now); err != nil { // Every worker touches one record
+				t.Errorf("MarkProcessed(shared): %v", err)
+			}
+			store.Processed(link)
+			store.SeenSince(link, now, false)
+		}()
+	}
+	waitGroup.Wait()
+
+	reloaded, err := LoadLinkStore(path, "") // What a later run would see
+	if err != nil {
+		t.Fatal(err)
+	}
+	for worker := range workers {
+		link := fmt.Sprintf("https://www.duragloss.com/sds/%d.pdf", worker)
+		if !reloaded.Processed(link) {
+			t.Errorf("%s missing from the saved store", link)
+		} else if size := reloaded.records[link].Size; size != int64(worker+1) {
+			t.Errorf("%s saved with size %d, want %d", link, size, worker+1)
+		}
+	}
+	if !reloaded.Processed("https://www.duragloss.com/sds/shared.pdf") {
+		t.Errorf("shared link missing from the saved store")
+	}
+}
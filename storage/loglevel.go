@@ -0,0 +1,7 @@
+package storage // Log level shared by the packages that report skipped work
+
+import "log/slog" // For the level type
+
+// LevelSkip is the slog level for links and files that were skipped rather than downloaded: below
+// Info, so a default run leaves them out and -v shows them
+const LevelSkip = slog.LevelInfo - 2
@@ -0,0 +1,111 @@
+package storage // Machine-readable manifest of downloaded PDFs
+
+import (
+	"encoding/json" // For encoding and decoding the manifest
+	"errors"        // For detecting a missing manifest
+	"fmt"           // For formatted error messages
+	"io/fs"         // For the not-exist sentinel error
+	"os"            // For reading and writing the manifest file
+	"sort"          // For writing entries in a stable order
+	"time"          // For download timestamps
+)
+
+// ManifestFile is the name of the manifest written next to the downloaded PDFs
+const ManifestFile = "manifest.json"
+
+// DownloadRecord describes one PDF saved by the downloader
+type DownloadRecord struct {
+	URL          string    `json:"url"`                     // Source URL the PDF was fetched from
+	Filename     string    `json:"filename"`                // Path the PDF was saved to
+	Size         int64     `json:"size"`                    // Number of bytes saved
+	SHA256       string    `json:"sha256"`                  // Hex SHA-256 of the content
+	HTTPStatus   int       `json:"http_status"`             // Status code of the download response
+	ContentType  string    `json:"content_type"`            // Content-Type header of the download response
+	ETag         string    `json:"etag,omitempty"`          // ETag header, sent back as If-None-Match on later runs
+	LastModified string    `json:"last_modified,omitempty"` // Last-Modified header, sent back as If-Modified-Since on later runs
+	DownloadedAt time.Time `json:"downloaded_at"`           // When the download finished
+	Duplicate    bool      `json:"duplicate,omitempty"`     // Same content as the file another URL saved under Filename
+
+	DurationMS     int64   `json:"duration_ms,omitempty"`      // Wall-clock time from request to saved body, in milliseconds
+	BytesPerSecond float64 `json:"bytes_per_second,omitempty"` // Bytes transferred in this download divided by its duration
+
+	Title     string     `json:"title,omitempty"`      // Document title from the PDF metadata
+	Author    string     `json:"author,omitempty"`     // Document author from the PDF metadata
+	Pages     int        `json:"pages,omitempty"`      // Number of pages in the PDF
+	CreatedAt *time.Time `json:"created_at,omitempty"` // Creation date from the PDF metadata
+
+	LinkTitle    string `json:"link_title,omitempty"`    // Product name from the link or its row on the scraped page
+	Category     string `json:"category,omitempty"`      // Nearest heading above the link on the scraped page
+	RevisionDate string `json:"revision_date,omitempty"` // Revision date printed next to the link, as YYYY-MM-DD
+}
+
+// LinkLabel is what the scraped page says about a document link
+type LinkLabel struct {
+	Title        string // Anchor text, or the row's first cell when the anchor only says "SDS" or similar
+	Category     string // Nearest heading or table caption above the link
+	RevisionDate string // Newest date in the link's row, as YYYY-MM-DD; empty when it shows none
+}
+
+// ReadManifest loads the records from an existing manifest, returning none if it doesn't exist
+func ReadManifest(path string) ([]DownloadRecord, error) {
+	content, err := os.ReadFile(path)   // Read the manifest file
+	if errors.Is(err, fs.ErrNotExist) { // No manifest yet
+		return nil, nil
+	}
+	if err != nil { // Handle read error
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	var entries []DownloadRecord                              // Records stored in the manifest
+	if err := json.Unmarshal(content, &entries); err != nil { // Decode the records
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// CanRevalidate returns true if the record holds a validator for a conditional GET
+func (record *DownloadRecord) CanRevalidate() bool {
+	return record != nil && (record.ETag != "" || record.LastModified != "")
+}
+
+// RecordsByURL indexes manifest records by their source URL
+func RecordsByURL(entries []DownloadRecord) map[string]*DownloadRecord {
+	byURL := make(map[string]*DownloadRecord, len(entries)) // URL -> record
+	for index := range entries {
+		byURL[entries[index].URL] = &entries[index]
+	}
+	return byURL
+}
+
+// MergeManifest replaces older records with newer ones for the same URL
+func MergeManifest(existing, updates []DownloadRecord) []DownloadRecord {
+	byURL := make(map[string]DownloadRecord) // Latest record for each URL
+	for _, entry := range existing {         // Start with the previous run's records
+		byURL[entry.URL] = entry
+	}
+	for _, entry := range updates { // Overlay this run's records
+		byURL[entry.URL] = entry
+	}
+
+	merged := make([]DownloadRecord, 0, len(byURL)) // Flattened record list
+	for _, entry := range byURL {
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
+// WriteManifest writes the records to path as indented JSON, sorted by URL
+func WriteManifest(entries []DownloadRecord, path string) error {
+	sort.Slice(entries, func(i, j int) bool { // Stable order keeps diffs between runs small
+		return entries[i].URL < entries[j].URL
+	})
+
+	content, err := json.MarshalIndent(entries, "", "  ") // Encode the records as readable JSON
+	if err != nil {                                       // Handle encode error
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := WriteFileAtomic(path, content, 0644); err != nil { // Replace the manifest whole
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage // Raw-HTML hashes for detecting unchanged index pages
+
+import (
+	"encoding/json" // For persisting the index as JSON
+	"errors"        // For detecting a missing index file
+	"fmt"           // For formatted error messages
+	"io/fs"         // For the not-exist sentinel error
+	"os"            // For reading and writing the index file
+	"sync"          // For guarding the index across parallel seeds
+)
+
+// PageHashIndex maps each scraped page URL to the SHA-256 of the raw HTML a plain GET returned
+// the last time the page was rendered
+type PageHashIndex struct {
+	mutex  sync.Mutex        // Guards hashes and the on-disk copy
+	path   string            // JSON file the index is persisted to
+	hashes map[string]string // Page URL -> hex SHA-256 of its raw HTML
+}
+
+// LoadPageHashIndex reads the index from disk, starting empty if the file doesn't exist yet
+func LoadPageHashIndex(path string) (*PageHashIndex, error) {
+	index := &PageHashIndex{path: path, hashes: make(map[string]string)} // Empty index bound to the path
+
+	content, err := os.ReadFile(path)   // Read the persisted index
+	if errors.Is(err, fs.ErrNotExist) { // First run: nothing to load
+		return index, nil
+	}
+	if err != nil { // Handle read error
+		return index, fmt.Errorf("failed to read page hash index %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, &index.hashes); err != nil { // Decode the stored hashes
+		return index, fmt.Errorf("failed to parse page hash index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// Get returns the stored hash for pageURL, or "" if the page hasn't been recorded
+func (index *PageHashIndex) Get(pageURL string) string {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	return index.hashes[pageURL]
+}
+
+// Set records hash for pageURL and persists the index
+func (index *PageHashIndex) Set(pageURL, hash string) error {
+	index.mutex.Lock()         // Serialize writes across seeds
+	defer index.mutex.Unlock() // Release the lock when done
+
+	if index.hashes[pageURL] == hash { // Nothing changed; skip the write
+		return nil
+	}
+	index.hashes[pageURL] = hash
+	content, err := json.MarshalIndent(index.hashes, "", "  ") // Encode the hashes as readable JSON
+	if err != nil {                                            // Handle encode error
+		return fmt.Errorf("failed to encode page hash index: %w", err)
+	}
+	if err := WriteFileAtomic(index.path, content, 0644); err != nil { // Replace the index file whole
+		return fmt.Errorf("failed to save page hash index: %w", err)
+	}
+	return nil
+}
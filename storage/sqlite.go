@@ -0,0 +1,144 @@
+package storage // SQLite backend for the processed-link store
+
+import (
+	"database/sql" // For the database handle
+	"errors"       // For detecting missing rows
+	"fmt"          // For formatted error messages
+	"log/slog"     // For structured logging
+	"time"         // For first-seen and check timestamps
+
+	_ "modernc.org/sqlite" // CGo-free SQLite driver registered as "sqlite"
+)
+
+// sqliteSchema creates the downloads table on first use
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS downloads (
+	url          TEXT PRIMARY KEY,
+	filename     TEXT NOT NULL DEFAULT '',
+	sha256       TEXT NOT NULL DEFAULT '',
+	size         INTEGER NOT NULL DEFAULT 0,
+	status       INTEGER NOT NULL DEFAULT 0,
+	first_seen   TEXT,
+	last_checked TEXT,
+	etag         TEXT NOT NULL DEFAULT ''
+)`
+
+// SQLiteStore keeps processed links and their latest download in a SQLite database
+type SQLiteStore struct {
+	db *sql.DB // Database handle, limited to one connection so workers queue instead of hitting "database is locked"
+}
+
+// OpenSQLiteStore opens or creates the database at path, importing the JSON link store at linkFile
+// when the database is new so switching backends keeps the history
+func OpenSQLiteStore(path, linkFile string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path) // Lazily opens the file
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1) // SQLite allows one writer at a time
+
+	store := &SQLiteStore{db: db}                    // Store bound to the database
+	if _, err := db.Exec(sqliteSchema); err != nil { // Create the table on first use
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema in %s: %w", path, err)
+	}
+	if err := store.importLinkStore(linkFile); err != nil { // Carry over earlier runs
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// importLinkStore copies the JSON link store into an empty database
+func (store *SQLiteStore) importLinkStore(linkFile string) error {
+	var rowCount int                                                                            // Rows already in the database
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM downloads`).Scan(&rowCount); err != nil { // Check whether anything is recorded
+		return fmt.Errorf("failed to count downloads: %w", err)
+	}
+	if rowCount > 0 { // Already in use; the JSON store is no longer authoritative
+		return nil
+	}
+
+	linkStore, err := LoadLinkStore(linkFile, LegacyLinkFile) // Earlier history, if any
+	if err != nil {
+		return err
+	}
+	for link, record := range linkStore.records { // Insert every known link
+		_, err := store.db.Exec(`INSERT INTO downloads (url, size, first_seen, last_checked) VALUES (?, ?, ?, ?)`,
+			link, record.Size, formatOptionalTime(record.FirstSeen), formatOptionalTime(record.LastDownloaded))
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", link, err)
+		}
+	}
+	if len(linkStore.records) > 0 { // Note the migration once
+		slog.Info("imported link store into database", "file", linkFile, "links", len(linkStore.records))
+	}
+	return nil
+}
+
+// Processed returns true if the link has been recorded before
+func (store *SQLiteStore) Processed(link string) bool {
+	var found int // Placeholder for the existence check
+	err := store.db.QueryRow(`SELECT 1 FROM downloads WHERE url = ?`, link).Scan(&found)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) { // Treat an unreadable row as unprocessed so the link is retried
+		slog.Error("failed to query database", "url", link, "error", err)
+	}
+	return err == nil
+}
+
+// SeenSince returns true if link was first seen at or after since, with the same rules as LinkStore.SeenSince
+func (store *SQLiteStore) SeenSince(link string, since time.Time, strict bool) bool {
+	var firstSeen sql.NullString // Stored first-seen time, if any
+	err := store.db.QueryRow(`SELECT first_seen FROM downloads WHERE url = ?`, link).Scan(&firstSeen)
+	if errors.Is(err, sql.ErrNoRows) { // Never seen before, so first seen now
+		return true
+	}
+	if err != nil { // Keep the link rather than silently dropping it
+		slog.Error("failed to query database", "url", link, "error", err)
+		return true
+	}
+	if !firstSeen.Valid { // Imported without a date
+		return !strict
+	}
+	seenAt, err := time.Parse(time.RFC3339Nano, firstSeen.String) // Stored as RFC 3339 text
+	if err != nil {                                               // Unreadable date counts as unknown
+		return !strict
+	}
+	return !seenAt.Before(since)
+}
+
+// MarkProcessed records that link was handled, updating its row from download when a file was fetched
+func (store *SQLiteStore) MarkProcessed(link string, download *DownloadRecord, now time.Time) error {
+	checkedAt := now.Format(time.RFC3339Nano) // Stored as RFC 3339 text
+	_, err := store.db.Exec(`INSERT INTO downloads (url, first_seen, last_checked) VALUES (?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET last_checked = excluded.last_checked`, link, checkedAt, checkedAt)
+	if err != nil { // Handle insert error
+		return fmt.Errorf("failed to record %s: %w", link, err)
+	}
+	if download == nil || download.Size <= 0 { // Nothing was fetched this time
+		return nil
+	}
+	_, err = store.db.Exec(`UPDATE downloads SET filename = ?, sha256 = ?, size = ?, status = ?, etag = ? WHERE url = ?`,
+		download.Filename, download.SHA256, download.Size, download.HTTPStatus, download.ETag, link)
+	if err != nil { // Handle update error
+		return fmt.Errorf("failed to record download of %s: %w", link, err)
+	}
+	return nil
+}
+
+// Flush is a no-op: every change is committed as it's made
+func (store *SQLiteStore) Flush() error {
+	return nil
+}
+
+// Close closes the database
+func (store *SQLiteStore) Close() error {
+	return store.db.Close()
+}
+
+// formatOptionalTime formats a nullable timestamp for storage
+func formatOptionalTime(moment *time.Time) any {
+	if moment == nil { // Unknown time
+		return nil
+	}
+	return moment.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,37 @@
+package storage // Plain-text URL lists for targeted downloads
+
+import (
+	"bufio"   // For reading the list line by line
+	"fmt"     // For formatted error messages
+	"io"      // For reading from stdin or a file alike
+	"os"      // For opening the list
+	"strings" // For trimming lines
+)
+
+// LoadURLList reads one URL per line from path, or from stdin when path is "-", skipping blank
+// lines and lines starting with #
+func LoadURLList(path string) ([]string, error) {
+	var source io.Reader = os.Stdin // "-" reads a piped list
+	if path != "-" {
+		file, err := os.Open(path) // A missing file is an error: the list was asked for
+		if err != nil {
+			return nil, fmt.Errorf("failed to read URL list %s: %w", path, err)
+		}
+		defer file.Close()
+		source = file
+	}
+
+	var links []string                  // URLs in file order
+	scanner := bufio.NewScanner(source) // Read one line at a time
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())       // Ignore indentation and CRLF endings
+		if line == "" || strings.HasPrefix(line, "#") { // Blank line or comment
+			continue
+		}
+		links = append(links, line)
+	}
+	if err := scanner.Err(); err != nil { // Handle read error
+		return nil, fmt.Errorf("failed to read URL list %s: %w", path, err)
+	}
+	return links, nil
+}
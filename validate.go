@@ -0,0 +1,60 @@
+package main // Post-download check that saved PDFs actually parse
+
+import (
+	"fmt"           // For the report lines
+	"io/fs"         // For walking the output directory
+	"log/slog"      // For structured logging
+	"os"            // For writing the report
+	"path/filepath" // For walking the output directory
+	"sort"          // For a stable report order
+	"strings"       // For matching extensions and building the report
+
+	"github.com/Tech-Trailblazers/duragloss-com-documentation/downloader" // PDF parsing
+)
+
+// validatePDFs opens each PDF in files with a real parser, logs the ones that fail and writes them to
+// reportPath as "file<TAB>error" lines, replacing any earlier report; it returns how many failed
+func validatePDFs(files []string, reportPath string) (int, error) {
+	var report strings.Builder // One line per bad file
+	checked, bad := 0, 0
+	for _, file := range files {
+		if !strings.EqualFold(filepath.Ext(file), ".pdf") { // Other document types have no parser here
+			continue
+		}
+		checked++
+		pages, err := downloader.ValidatePDF(file)
+		if err != nil {
+			slog.Error("saved PDF doesn't parse", "file", file, "error", err)
+			fmt.Fprintf(&report, "%s\t%v\n", file, err)
+			bad++
+			continue
+		}
+		slog.Debug("validated PDF", "file", file, "pages", pages)
+	}
+	if err := os.WriteFile(reportPath, []byte(report.String()), 0644); err != nil { // An empty report means everything parsed
+		return bad, fmt.Errorf("failed to write validation report %s: %w", reportPath, err)
+	}
+	slog.Info("validated PDFs", "checked", checked, "bad", bad, "report", reportPath)
+	return bad, nil
+}
+
+// savedFiles returns every file under outputDir except partial downloads; that includes the manifest, the
+// mirror index and any reports, which validatePDFs passes over because it only checks .pdf files
+func savedFiles(outputDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(outputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, ".part") { // Directories and unfinished downloads
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", outputDir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}